@@ -0,0 +1,294 @@
+package daox
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/fengjx/daox/engine"
+	"github.com/fengjx/daox/sqlbuilder"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+// AuditOp 审计的操作类型
+type AuditOp string
+
+const (
+	AuditOpUpdate AuditOp = "update" // 更新
+	AuditOpDelete AuditOp = "delete" // 删除
+)
+
+// DefaultAuditTable 内置审计表名
+const DefaultAuditTable = "audit_log"
+
+// AuditRecord 一行数据的变更记录
+type AuditRecord struct {
+	Table  string         `json:"table"`  // 表名
+	PK     any            `json:"pk"`     // 主键值
+	Op     AuditOp        `json:"op"`     // 操作类型
+	Before map[string]any `json:"before"` // 变更前的数据，更新时只包含变化的字段，删除时为整行数据
+	After  map[string]any `json:"after"`  // 变更后的数据，只包含变化的字段，删除时为 nil
+	Actor  any            `json:"actor"`  // 操作人，通过 WithActor 设置到上下文中
+	At     time.Time      `json:"at"`     // 变更时间
+}
+
+// AuditSink 审计记录写入器
+// 在变更数据的同一个事务中调用，executor 为当前事务，返回错误时事务回滚
+type AuditSink interface {
+	Write(ctx context.Context, executor engine.Executor, records []*AuditRecord) error
+}
+
+// AuditSinkFunc 函数形式的 AuditSink
+type AuditSinkFunc func(ctx context.Context, executor engine.Executor, records []*AuditRecord) error
+
+// Write 写入审计记录
+func (f AuditSinkFunc) Write(ctx context.Context, executor engine.Executor, records []*AuditRecord) error {
+	return f(ctx, executor, records)
+}
+
+type actorCtxKey struct{}
+
+// WithActor 在上下文中设置操作人
+func WithActor(ctx context.Context, actor any) context.Context {
+	return context.WithValue(ctx, actorCtxKey{}, actor)
+}
+
+// GetActor 获取上下文中的操作人
+func GetActor(ctx context.Context) (any, bool) {
+	actor := ctx.Value(actorCtxKey{})
+	return actor, actor != nil
+}
+
+// AuditLog 内置审计表结构，可以通过 AuditLogMeta 和 AutoMigrate 创建
+type AuditLog struct {
+	ID         int64     `json:"id" daox:"comment:主键"`
+	TableName  string    `json:"table_name" daox:"size:64;index:idx_audit_table_pk;comment:表名"`
+	PK         string    `json:"pk" daox:"size:64;index:idx_audit_table_pk;comment:主键值"`
+	Op         string    `json:"op" daox:"size:16;comment:操作类型"`
+	BeforeData string    `json:"before_data" daox:"type:text;comment:变更前的数据"`
+	AfterData  string    `json:"after_data" daox:"type:text;comment:变更后的数据"`
+	Actor      string    `json:"actor" daox:"size:64;comment:操作人"`
+	Ctime      time.Time `json:"ctime" daox:"comment:变更时间"`
+}
+
+// AuditLogMeta 内置审计表元信息，tableName 为空时使用 DefaultAuditTable
+func AuditLogMeta(tableName string) *TableMeta {
+	if tableName == "" {
+		tableName = DefaultAuditTable
+	}
+	mapper := sqlbuilder.GetMapperByTagName("json")
+	typ := reflect.TypeFor[AuditLog]()
+	meta := &TableMeta{
+		TableName:       tableName,
+		PrimaryKey:      "id",
+		IsAutoIncrement: true,
+		Columns:         sqlbuilder.GetColumnsByType(mapper, typ),
+	}
+	meta.ColumnMetas, meta.Indexes = parseColumnMetas(mapper, typ, tableName, meta.Columns)
+	return meta
+}
+
+// NewTableAuditSink 创建写入审计表的 AuditSink，表结构见 AuditLog，tableName 为空时使用 DefaultAuditTable
+// 变更数据序列化成 json 保存，审计记录与变更数据在同一个事务中写入
+func NewTableAuditSink(tableName string) AuditSink {
+	if tableName == "" {
+		tableName = DefaultAuditTable
+	}
+	return &tableAuditSink{tableName: tableName}
+}
+
+type tableAuditSink struct {
+	tableName string
+}
+
+func (s *tableAuditSink) Write(ctx context.Context, executor engine.Executor, records []*AuditRecord) error {
+	rows := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		before, err := marshalAuditData(record.Before)
+		if err != nil {
+			return err
+		}
+		after, err := marshalAuditData(record.After)
+		if err != nil {
+			return err
+		}
+		actor := ""
+		if record.Actor != nil {
+			actor = fmt.Sprint(record.Actor)
+		}
+		rows = append(rows, map[string]any{
+			"table_name":  record.Table,
+			"pk":          fmt.Sprint(record.PK),
+			"op":          string(record.Op),
+			"before_data": before,
+			"after_data":  after,
+			"actor":       actor,
+			"ctime":       record.At,
+		})
+	}
+	_, err := BatchInsert(ctx, executor, BatchInsertRecord{TableName: s.tableName, Rows: rows})
+	return err
+}
+
+func marshalAuditData(data map[string]any) (string, error) {
+	if data == nil {
+		return "", nil
+	}
+	bs, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// auditSink 未开启审计时返回 nil
+func (d *Dao) auditSink() AuditSink {
+	if d.options == nil {
+		return nil
+	}
+	return d.options.auditSink
+}
+
+// auditWrite 执行更新、删除，开启审计时在同一个事务中加载变更前的数据，执行后对比变更后的数据并写入审计记录
+// where 为 name 风格时通过 namedArgs 填充参数，write 需要使用传入的 dao 执行
+func (d *Dao) auditWrite(ctx context.Context, op AuditOp, where sqlbuilder.ConditionBuilder, namedArgs any,
+	write func(ctx context.Context, dao *Dao) (int64, error)) (int64, error) {
+	sink := d.auditSink()
+	if sink == nil {
+		return write(ctx, d)
+	}
+	var affected int64
+	err := d.auditTx(ctx, func(txCtx context.Context, executor engine.Executor) error {
+		txDao := d.WithExecutor(executor)
+		selector := txDao.Selector().Where(where).NamedArgs(namedArgs)
+		if dialectOfExecer(executor) == DialectMySQL {
+			selector.ForUpdate(true)
+		}
+		before, err := loadAuditRows(txCtx, selector)
+		if err != nil {
+			return err
+		}
+		if affected, err = write(txCtx, txDao); err != nil {
+			return err
+		}
+		if len(before) == 0 || affected == 0 {
+			return nil
+		}
+		pk := d.TableMeta.PrimaryKey
+		afterMap := make(map[any]map[string]any)
+		if op == AuditOpUpdate {
+			pks := make([]any, 0, len(before))
+			for _, row := range before {
+				pks = append(pks, row[pk])
+			}
+			after, err := loadAuditRows(txCtx, txDao.Selector().Where(ql.C(ql.Col(pk).In(pks...))))
+			if err != nil {
+				return err
+			}
+			for _, row := range after {
+				afterMap[auditKey(row[pk])] = row
+			}
+		}
+		records := diffAuditRows(d.TableMeta.TableName, pk, op, before, afterMap)
+		if len(records) == 0 {
+			return nil
+		}
+		actor, _ := GetActor(txCtx)
+		now := time.Now()
+		for _, record := range records {
+			record.Actor = actor
+			record.At = now
+		}
+		return sink.Write(txCtx, executor, records)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// auditNamedArgs 使用 dao 的字段映射把 model 转换成 map，用于填充 name 风格的查询条件
+func (d *Dao) auditNamedArgs(model any) map[string]any {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	fields := d.mapper.FieldMap(v)
+	args := make(map[string]any, len(fields))
+	for name, field := range fields {
+		args[name] = field.Interface()
+	}
+	return args
+}
+
+// auditTx 在事务中执行，已经在事务中时直接使用当前事务
+func (d *Dao) auditTx(ctx context.Context, fn TxFun) error {
+	if d.executor != nil {
+		return fn(ctx, d.executor)
+	}
+	m := &TxManager{db: d.GetMasterDB()}
+	return m.ExecTx(ctx, fn)
+}
+
+func loadAuditRows(ctx context.Context, selector *sqlbuilder.Selector) ([]map[string]any, error) {
+	var list []map[string]any
+	err := selector.StreamContext(ctx, func(rows *sql.Rows) (int64, error) {
+		var err error
+		list, err = ScanMap(rows)
+		return int64(len(list)), err
+	})
+	return list, err
+}
+
+// diffAuditRows 对比变更前后的数据，更新时没有变化的行不生成审计记录
+func diffAuditRows(tableName, pk string, op AuditOp, before []map[string]any, afterMap map[any]map[string]any) []*AuditRecord {
+	records := make([]*AuditRecord, 0, len(before))
+	for _, row := range before {
+		record := &AuditRecord{Table: tableName, PK: row[pk], Op: op}
+		if op == AuditOpDelete {
+			record.Before = row
+			records = append(records, record)
+			continue
+		}
+		after, ok := afterMap[auditKey(row[pk])]
+		if !ok {
+			// 主键被修改，无法对比
+			record.Before = row
+			records = append(records, record)
+			continue
+		}
+		record.Before = make(map[string]any)
+		record.After = make(map[string]any)
+		for col, val := range row {
+			if newVal := after[col]; !auditValueEqual(val, newVal) {
+				record.Before[col] = val
+				record.After[col] = newVal
+			}
+		}
+		if len(record.After) > 0 {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// auditKey 主键值转换成可以作为 map key 的值
+func auditKey(val any) any {
+	if bs, ok := val.([]byte); ok {
+		return string(bs)
+	}
+	return val
+}
+
+func auditValueEqual(a, b any) bool {
+	switch av := a.(type) {
+	case time.Time:
+		bv, ok := b.(time.Time)
+		return ok && av.Equal(bv)
+	case []byte:
+		bv, ok := b.([]byte)
+		return ok && bytes.Equal(av, bv)
+	}
+	return reflect.DeepEqual(a, b)
+}
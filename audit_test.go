@@ -0,0 +1,163 @@
+package daox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/engine"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+type auditUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Age  int64  `json:"age"`
+}
+
+func (m *auditUser) GetID() any {
+	return m.ID
+}
+
+func TestAudit(t *testing.T) {
+	tableName := "audit_user"
+	after(t, tableName)
+	after(t, daox.DefaultAuditTable)
+	defer after(t, tableName)
+	defer after(t, daox.DefaultAuditTable)
+	db := newDb()
+	_, err := db.Exec("CREATE TABLE audit_user (id integer primary key autoincrement, name text, age integer)")
+	assert.NoError(t, err)
+	assert.NoError(t, daox.AutoMigrate(context.Background(), db, daox.AuditLogMeta("")))
+
+	dao := daox.NewDao[*auditUser](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(db),
+		daox.WithAudit(daox.NewTableAuditSink("")),
+	)
+	ctx := daox.WithActor(context.Background(), "admin")
+	_, err = dao.BatchSave([]*auditUser{{Name: "u1", Age: 10}, {Name: "u2", Age: 20}, {Name: "u3", Age: 30}})
+	assert.NoError(t, err)
+
+	loadLogs := func() []daox.AuditLog {
+		var logs []daox.AuditLog
+		assert.NoError(t, db.Select(&logs, "SELECT * FROM audit_log ORDER BY id"))
+		return logs
+	}
+	decode := func(data string) map[string]any {
+		if data == "" {
+			return nil
+		}
+		m := make(map[string]any)
+		assert.NoError(t, json.Unmarshal([]byte(data), &m))
+		return m
+	}
+
+	// 部分字段更新，只记录变化的字段
+	ok, err := dao.UpdateFieldContext(ctx, 1, map[string]any{"name": "u1-new", "age": 10})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	logs := loadLogs()
+	assert.Len(t, logs, 1)
+	assert.Equal(t, tableName, logs[0].TableName)
+	assert.Equal(t, "1", logs[0].PK)
+	assert.Equal(t, "update", logs[0].Op)
+	assert.Equal(t, "admin", logs[0].Actor)
+	assert.Equal(t, map[string]any{"name": "u1"}, decode(logs[0].BeforeData))
+	assert.Equal(t, map[string]any{"name": "u1-new"}, decode(logs[0].AfterData))
+
+	// 数据没有变化时不记录
+	ok, err = dao.UpdateContext(ctx, &auditUser{ID: 1, Name: "u1-new", Age: 10})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, loadLogs(), 1)
+
+	// name 风格条件更新
+	ok, err = dao.UpdateByCondContext(ctx, &auditUser{Name: "same", Age: 15},
+		ql.SC().And("age > :age"), "id")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	logs = loadLogs()
+	assert.Len(t, logs, 3)
+	assert.Equal(t, "2", logs[1].PK)
+	assert.Equal(t, map[string]any{"name": "u2", "age": float64(20)}, decode(logs[1].BeforeData))
+	assert.Equal(t, map[string]any{"name": "same", "age": float64(15)}, decode(logs[1].AfterData))
+	assert.Equal(t, "3", logs[2].PK)
+
+	// 删除记录整行数据
+	ok, err = dao.DeleteByIDContext(ctx, 3)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	logs = loadLogs()
+	assert.Len(t, logs, 4)
+	assert.Equal(t, "delete", logs[3].Op)
+	assert.Equal(t, map[string]any{"id": float64(3), "name": "same", "age": float64(15)}, decode(logs[3].BeforeData))
+	assert.Empty(t, logs[3].AfterData)
+
+	// 没有匹配的数据
+	affected, err := dao.DeleteByColumnContext(ctx, daox.OfKv("name", "not_exist"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+	assert.Len(t, loadLogs(), 4)
+}
+
+type auditFailKey struct{}
+
+func TestAuditRollback(t *testing.T) {
+	tableName := "audit_user"
+	after(t, tableName)
+	defer after(t, tableName)
+	db := newDb()
+	_, err := db.Exec("CREATE TABLE audit_user (id integer primary key autoincrement, name text, age integer)")
+	assert.NoError(t, err)
+
+	errSink := errors.New("sink failed")
+	var records []*daox.AuditRecord
+	dao := daox.NewDao[*auditUser](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(db),
+		daox.WithAudit(daox.AuditSinkFunc(func(ctx context.Context, executor engine.Executor, list []*daox.AuditRecord) error {
+			records = append(records, list...)
+			if ctx.Value(auditFailKey{}) != nil {
+				return errSink
+			}
+			return nil
+		})),
+	)
+	id, err := dao.Save(&auditUser{Name: "u1", Age: 10})
+	assert.NoError(t, err)
+
+	// sink 失败时回滚更新
+	failCtx := context.WithValue(context.Background(), auditFailKey{}, true)
+	_, err = dao.UpdateFieldContext(failCtx, id, map[string]any{"age": 11})
+	assert.ErrorIs(t, err, errSink)
+	user := &auditUser{}
+	_, err = dao.GetByID(id, user)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), user.Age)
+
+	// 在外部事务中执行，外部事务回滚时一起回滚
+	errTx := errors.New("tx failed")
+	err = daox.NewTxManager(db).ExecTx(context.Background(), func(txCtx context.Context, executor engine.Executor) error {
+		ok, err := dao.WithExecutor(executor).DeleteByIDContext(txCtx, id)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		return errTx
+	})
+	assert.ErrorIs(t, err, errTx)
+	_, err = dao.GetByID(id, user)
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", user.Name)
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, daox.AuditOpUpdate, records[0].Op)
+	assert.Equal(t, map[string]any{"age": int64(10)}, records[0].Before)
+	assert.Equal(t, map[string]any{"age": int64(11)}, records[0].After)
+	assert.Equal(t, daox.AuditOpDelete, records[1].Op)
+	assert.Equal(t, id, records[1].PK)
+	assert.Nil(t, records[1].Actor)
+}
@@ -0,0 +1,565 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ddlIntrospector 解析 sql 文件中的 CREATE TABLE 语句，不需要连接数据库
+// dsn 可以是 sql 文件、目录或者 glob 表达式，目录会读取所有 .sql 文件
+type ddlIntrospector struct {
+	tables map[string]*Table
+}
+
+func newDDLIntrospector(dsn string) (*ddlIntrospector, error) {
+	files, err := ddlFiles(dsn)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]*Table)
+	for _, file := range files {
+		bs, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		list, err := parseDDL(string(bs))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, table := range list {
+			tables[table.Name] = table
+		}
+	}
+	return &ddlIntrospector{tables: tables}, nil
+}
+
+func (d *ddlIntrospector) TableNames() ([]string, error) {
+	names := make([]string, 0, len(d.tables))
+	for name := range d.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (d *ddlIntrospector) LoadTable(tableName string) (*Table, error) {
+	table, ok := d.tables[tableName]
+	if !ok {
+		return nil, fmt.Errorf("table %s not found in ddl files", tableName)
+	}
+	return table, nil
+}
+
+func (d *ddlIntrospector) Close() error {
+	return nil
+}
+
+func ddlFiles(dsn string) ([]string, error) {
+	info, err := os.Stat(dsn)
+	if err == nil && !info.IsDir() {
+		return []string{dsn}, nil
+	}
+	pattern := dsn
+	if err == nil {
+		pattern = filepath.Join(dsn, "*.sql")
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no ddl file found in %s", dsn)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ddlToken 类型
+const (
+	tokenWord    = iota // 关键字、未加引号的标识符、数字
+	tokenIdent          // 加了引号的标识符，eg: `name` "name" [name]
+	tokenString         // 字符串，eg: 'abc'
+	tokenSymbol         // 符号，eg: ( ) , ; =
+	tokenComment        // 单行注释，eg: -- comment
+)
+
+type ddlToken struct {
+	kind int
+	text string
+	line int
+}
+
+// is 判断是否是指定的关键字，忽略大小写
+func (t ddlToken) is(keywords ...string) bool {
+	if t.kind != tokenWord && t.kind != tokenSymbol {
+		return false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(t.text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize 将 sql 拆分成 ddlToken，多行注释会被忽略
+func tokenize(text string) ([]ddlToken, error) {
+	var tokens []ddlToken
+	runes := []rune(text)
+	line := 1
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			line++
+		case unicode.IsSpace(c):
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-', c == '#':
+			start := i
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			comment := strings.TrimLeft(string(runes[start:i]), "-# ")
+			tokens = append(tokens, ddlToken{kind: tokenComment, text: strings.TrimSpace(comment), line: line})
+			line++
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			i++
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			var sb strings.Builder
+			startLine := line
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\n' {
+					line++
+				}
+				if runes[i] == '\\' && c == '\'' && i+1 < len(runes) {
+					i++
+					sb.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == end {
+					// 两个连续的引号表示转义
+					if i+1 < len(runes) && runes[i+1] == end && end != ']' {
+						sb.WriteRune(end)
+						i++
+						continue
+					}
+					closed = true
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unclosed quote %c", startLine, c)
+			}
+			kind := tokenIdent
+			if c == '\'' {
+				kind = tokenString
+			}
+			tokens = append(tokens, ddlToken{kind: kind, text: sb.String(), line: line})
+		case strings.ContainsRune("(),;=.", c):
+			tokens = append(tokens, ddlToken{kind: tokenSymbol, text: string(c), line: line})
+		default:
+			start := i
+			for i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && !strings.ContainsRune("(),;=.'\"`[", runes[i+1]) {
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: tokenWord, text: string(runes[start : i+1]), line: line})
+		}
+	}
+	return tokens, nil
+}
+
+// parseDDL 解析 CREATE TABLE 语句，支持 mysql 和 sqlite 语法，其他语句会被忽略
+func parseDDL(text string) ([]*Table, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*Table
+	var stmt []ddlToken
+	for _, tok := range append(tokens, ddlToken{kind: tokenSymbol, text: ";"}) {
+		if !tok.is(";") {
+			stmt = append(stmt, tok)
+			continue
+		}
+		if tableName, index, ok := parseCreateIndex(stmt); ok {
+			for _, table := range tables {
+				if table.Name == tableName {
+					table.Indexes = append(table.Indexes, index)
+					resolveFinders(table)
+				}
+			}
+			stmt = nil
+			continue
+		}
+		table, err := parseCreateTable(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if table != nil {
+			tables = append(tables, table)
+		}
+		stmt = nil
+	}
+	return tables, nil
+}
+
+// parseCreateTable 解析单条 CREATE TABLE 语句，不是建表语句时返回 nil
+func parseCreateTable(stmt []ddlToken) (*Table, error) {
+	tokens := withoutComments(stmt)
+	// commentless[i] 是 tokens[i] 在 stmt 中的位置
+	commentless := make([]int, 0, len(tokens))
+	for j, tok := range stmt {
+		if tok.kind != tokenComment {
+			commentless = append(commentless, j)
+		}
+	}
+	i := 0
+	if len(tokens) < 3 || !tokens[0].is("CREATE") {
+		return nil, nil
+	}
+	i++
+	for i < len(tokens) && tokens[i].is("TEMPORARY", "TEMP") {
+		i++
+	}
+	if i >= len(tokens) || !tokens[i].is("TABLE") {
+		return nil, nil
+	}
+	i++
+	if i+2 < len(tokens) && tokens[i].is("IF") && tokens[i+1].is("NOT") && tokens[i+2].is("EXISTS") {
+		i += 3
+	}
+	if i >= len(tokens) {
+		return nil, fmt.Errorf("line %d: table name requires", tokens[len(tokens)-1].line)
+	}
+	tableName := tokens[i].text
+	i++
+	// schema.table
+	for i+1 < len(tokens) && tokens[i].is(".") {
+		tableName = tokens[i+1].text
+		i += 2
+	}
+	if i >= len(tokens) || !tokens[i].is("(") {
+		return nil, fmt.Errorf("line %d: table %s: expected (", tokens[i-1].line, tableName)
+	}
+	// 找到字段定义对应的 ddlToken 范围，保留注释用于读取 sqlite 的字段注释
+	start := commentless[i]
+	end := matchParen(stmt, start)
+	if end < 0 {
+		return nil, fmt.Errorf("table %s: unclosed (", tableName)
+	}
+	var (
+		columns     []Column
+		primaryKeys []string
+		indexes     []Index
+		autoIncr    bool
+	)
+	for _, def := range splitDefinitions(stmt[start+1 : end]) {
+		defTokens := withoutComments(def)
+		if len(defTokens) == 0 {
+			continue
+		}
+		first := defTokens[0]
+		if first.kind == tokenWord && first.is("PRIMARY", "KEY", "INDEX", "UNIQUE", "CONSTRAINT",
+			"FOREIGN", "FULLTEXT", "SPATIAL", "CHECK") {
+			if index, ok := parseIndex(defTokens); ok {
+				if index.Primary {
+					primaryKeys = index.Columns
+				}
+				indexes = append(indexes, index)
+			}
+			continue
+		}
+		col, pk, unique, err := parseColumn(defTokens)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", tableName, err)
+		}
+		// 字段定义后面同一行的注释作为字段注释
+		if col.Comment == "" {
+			lastLine := defTokens[len(defTokens)-1].line
+			for _, tok := range stmt[start+1 : end+1] {
+				if tok.kind == tokenComment && tok.line == lastLine {
+					col.Comment = tok.text
+				}
+			}
+		}
+		if pk {
+			primaryKeys = []string{col.Name}
+		}
+		if unique {
+			indexes = append(indexes, Index{Name: col.Name, Unique: true, Columns: []string{col.Name}})
+		}
+		if col.Extra == "auto_increment" {
+			autoIncr = true
+		}
+		columns = append(columns, col)
+	}
+	for i := range columns {
+		for _, pk := range primaryKeys {
+			if strings.EqualFold(columns[i].Name, pk) {
+				columns[i].IsPrimaryKey = true
+				columns[i].Nullable = false
+			}
+		}
+	}
+	table := newTable(tableName, tableComment(withoutComments(stmt[end+1:])), columns, indexes)
+	table.AutoIncrement = autoIncr
+	return table, nil
+}
+
+// parseColumn 解析字段定义，返回字段、是否主键、是否唯一
+func parseColumn(tokens []ddlToken) (Column, bool, bool, error) {
+	if len(tokens) < 2 {
+		return Column{}, false, false, fmt.Errorf("line %d: invalid column definition", tokens[0].line)
+	}
+	col := Column{
+		Name:    tokens[0].text,
+		SQLType: strings.ToUpper(tokens[1].text),
+	}
+	pk := false
+	unique := false
+	notNull := false
+	i := 2
+	columnType := tokens[1].text
+	// 类型参数，eg: varchar(32) decimal(10, 2) enum('a', 'b')
+	if i < len(tokens) && tokens[i].is("(") {
+		if end := matchParen(tokens, i); end > 0 {
+			columnType += "(" + joinTokens(tokens[i+1:end]) + ")"
+			i = end + 1
+		}
+	}
+	for ; i < len(tokens) && tokens[i].is("UNSIGNED", "ZEROFILL"); i++ {
+		columnType += " " + strings.ToLower(tokens[i].text)
+	}
+	parseColumnType(&col, columnType)
+	for ; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.is("NOT") && i+1 < len(tokens) && tokens[i+1].is("NULL"):
+			notNull = true
+			i++
+		case tok.is("DEFAULT") && i+1 < len(tokens):
+			i++
+			if tokens[i].is("(") {
+				end := matchParen(tokens, i)
+				if end < 0 {
+					end = len(tokens) - 1
+				}
+				col.DefaultValue = joinTokens(tokens[i+1 : end])
+				i = end
+			} else if !tokens[i].is("NULL") {
+				// DEFAULT NULL 等同于没有默认值
+				col.DefaultValue = tokens[i].text
+			}
+		case tok.is("AUTO_INCREMENT", "AUTOINCREMENT"):
+			col.Extra = "auto_increment"
+		case tok.is("PRIMARY"):
+			pk = true
+		case tok.is("UNIQUE"):
+			unique = true
+		case tok.is("COMMENT") && i+1 < len(tokens):
+			i++
+			col.Comment = tokens[i].text
+		}
+	}
+	// sqlite 中声明类型为 INTEGER 的主键是 rowid 的别名，插入时自动生成，与 NOT NULL 无关
+	if pk && strings.EqualFold(columnType, "INTEGER") && col.Extra == "" {
+		col.Extra = "auto_increment"
+	}
+	col.Nullable = !notNull && !pk
+	return col, pk, unique, nil
+}
+
+// parseIndex 解析表级索引定义，全文索引、外键等不需要的定义返回 false
+// eg: PRIMARY KEY (`id`)、UNIQUE KEY `uni_uid` (`uid`)、KEY idx_a_b (a, b(10))、CONSTRAINT pk PRIMARY KEY (id)
+func parseIndex(tokens []ddlToken) (Index, bool) {
+	index := Index{}
+	i := 0
+	if tokens[i].is("CONSTRAINT") {
+		i++
+		if i < len(tokens) && !tokens[i].is("PRIMARY", "UNIQUE", "FOREIGN", "CHECK") {
+			index.Name = tokens[i].text
+			i++
+		}
+	}
+	if i >= len(tokens) {
+		return index, false
+	}
+	switch {
+	case tokens[i].is("PRIMARY"):
+		index.Name = "PRIMARY"
+		index.Primary = true
+		index.Unique = true
+	case tokens[i].is("UNIQUE"):
+		index.Unique = true
+	case tokens[i].is("KEY", "INDEX"):
+	default:
+		return index, false
+	}
+	for i++; i < len(tokens) && !tokens[i].is("("); i++ {
+		if !tokens[i].is("KEY", "INDEX", "USING", "BTREE", "HASH") {
+			index.Name = tokens[i].text
+		}
+	}
+	if i >= len(tokens) {
+		return index, false
+	}
+	index.Columns = indexColumns(tokens[i:])
+	if index.Name == "" && len(index.Columns) > 0 {
+		index.Name = index.Columns[0]
+	}
+	return index, len(index.Columns) > 0
+}
+
+// indexColumns 解析索引字段列表，忽略前缀长度和排序，eg: (a, b(10) DESC)
+func indexColumns(tokens []ddlToken) []string {
+	end := matchParen(tokens, 0)
+	if end < 0 {
+		return nil
+	}
+	var columns []string
+	depth := 0
+	for _, tok := range tokens[1:end] {
+		switch {
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		case depth == 0 && (tok.kind == tokenIdent || (tok.kind == tokenWord && !tok.is("ASC", "DESC", "COLLATE"))):
+			columns = append(columns, tok.text)
+		}
+	}
+	return columns
+}
+
+// parseCreateIndex 解析 CREATE [UNIQUE] INDEX [IF NOT EXISTS] name ON table (columns) 语句
+func parseCreateIndex(stmt []ddlToken) (string, Index, bool) {
+	tokens := withoutComments(stmt)
+	index := Index{}
+	i := 0
+	if len(tokens) < 5 || !tokens[i].is("CREATE") {
+		return "", index, false
+	}
+	i++
+	if tokens[i].is("UNIQUE") {
+		index.Unique = true
+		i++
+	}
+	if !tokens[i].is("INDEX") {
+		return "", index, false
+	}
+	i++
+	if i+2 < len(tokens) && tokens[i].is("IF") && tokens[i+1].is("NOT") && tokens[i+2].is("EXISTS") {
+		i += 3
+	}
+	if i+2 >= len(tokens) {
+		return "", index, false
+	}
+	index.Name = tokens[i].text
+	i++
+	if !tokens[i].is("ON") {
+		return "", index, false
+	}
+	tableName := tokens[i+1].text
+	i += 2
+	for i+1 < len(tokens) && tokens[i].is(".") {
+		tableName = tokens[i+1].text
+		i += 2
+	}
+	if i >= len(tokens) || !tokens[i].is("(") {
+		return "", index, false
+	}
+	index.Columns = indexColumns(tokens[i:])
+	return tableName, index, len(index.Columns) > 0
+}
+
+// tableComment 读取表选项中的注释，eg: ENGINE=InnoDB COMMENT='用户表'
+func tableComment(tokens []ddlToken) string {
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].is("COMMENT") {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1].is("=") {
+			i++
+		}
+		if i+1 < len(tokens) && tokens[i+1].kind == tokenString {
+			return tokens[i+1].text
+		}
+	}
+	return ""
+}
+
+// splitDefinitions 按最外层的逗号拆分字段和约束定义
+func splitDefinitions(tokens []ddlToken) [][]ddlToken {
+	var (
+		defs  [][]ddlToken
+		def   []ddlToken
+		depth int
+	)
+	for _, tok := range tokens {
+		switch {
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		case tok.is(",") && depth == 0:
+			defs = append(defs, def)
+			def = nil
+			continue
+		}
+		def = append(def, tok)
+	}
+	return append(defs, def)
+}
+
+func matchParen(tokens []ddlToken, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		if tokens[i].is("(") {
+			depth++
+		} else if tokens[i].is(")") {
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func withoutComments(tokens []ddlToken) []ddlToken {
+	result := make([]ddlToken, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.kind != tokenComment {
+			result = append(result, tok)
+		}
+	}
+	return result
+}
+
+func joinTokens(tokens []ddlToken) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		if tok.kind == tokenString {
+			sb.WriteString("'" + strings.ReplaceAll(tok.text, "'", "''") + "'")
+			continue
+		}
+		sb.WriteString(tok.text)
+	}
+	return sb.String()
+}
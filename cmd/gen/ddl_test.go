@@ -0,0 +1,140 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDDL(t *testing.T) {
+	tables, err := parseDDL(`
+-- 用户表
+DROP TABLE IF EXISTS user_info;
+CREATE TABLE IF NOT EXISTS ` + "`demo`.`user_info`" + ` (
+  ` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  ` + "`nickname`" + ` varchar(32) DEFAULT '' COMMENT '昵称; ''别名''',
+  ` + "`amount`" + ` decimal(10, 2) NOT NULL DEFAULT '0.00',
+  ` + "`ctime`" + ` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (` + "`id`" + `),
+  UNIQUE KEY ` + "`uni_nickname`" + ` (` + "`nickname`" + `)
+) ENGINE=InnoDB AUTO_INCREMENT=10 DEFAULT CHARSET=utf8mb4 COMMENT='用户信息表';
+
+/* sqlite */
+create table blog (
+  id integer primary key, -- 主键
+  uid integer not null,
+  title text default (lower('a,b')), -- 标题
+  "content" text
+);
+CREATE INDEX idx_uid ON blog (uid);
+create table blog_tag (blog_id integer, tag varchar(10), primary key (blog_id, tag))
+`)
+	assert.NoError(t, err)
+	assert.Len(t, tables, 3)
+
+	user := tables[0]
+	assert.Equal(t, "user_info", user.Name)
+	assert.Equal(t, "UserInfo", user.StructName)
+	assert.Equal(t, "用户信息表", user.Comment)
+	assert.True(t, user.AutoIncrement)
+	assert.Equal(t, "id", user.PrimaryKey.Name)
+	assert.Equal(t, []string{"time"}, user.GoImports)
+	assert.Equal(t, []Column{
+		{TableName: "user_info", Name: "id", FieldName: "ID", SQLType: "BIGINT", ColumnType: "bigint unsigned", Comment: "主键",
+			IsPrimaryKey: true, Extra: "auto_increment", Unsigned: true, GoType: "uint64", ArgType: "uint64"},
+		{TableName: "user_info", Name: "nickname", FieldName: "Nickname", SQLType: "VARCHAR", ColumnType: "varchar(32)", Comment: "昵称; '别名'",
+			Nullable: true, Length: 32, GoType: "string", ArgType: "string"},
+		{TableName: "user_info", Name: "amount", FieldName: "Amount", SQLType: "DECIMAL", ColumnType: "decimal(10,2)", DefaultValue: "0.00",
+			Precision: 10, Scale: 2, GoType: "string", ArgType: "string"},
+		{TableName: "user_info", Name: "ctime", FieldName: "Ctime", SQLType: "DATETIME", ColumnType: "datetime", DefaultValue: "CURRENT_TIMESTAMP",
+			GoType: "time.Time", ArgType: "time.Time"},
+	}, user.Columns)
+	assert.Equal(t, []Index{
+		{Name: "PRIMARY", Unique: true, Primary: true, Columns: []string{"id"}},
+		{Name: "uni_nickname", Unique: true, Columns: []string{"nickname"}},
+	}, user.Indexes)
+	assert.Equal(t, []Finder{
+		{Name: "GetByNickname", Unique: true, Columns: []FinderParam{{Column: "nickname", Field: "Nickname", Name: "nickname", Type: "string"}}},
+	}, user.Finders)
+
+	blog := tables[1]
+	assert.Equal(t, "blog", blog.Name)
+	assert.True(t, blog.AutoIncrement)
+	assert.Equal(t, []Column{
+		{TableName: "blog", Name: "id", FieldName: "ID", SQLType: "INTEGER", ColumnType: "integer", Comment: "主键",
+			IsPrimaryKey: true, Extra: "auto_increment", GoType: "int32", ArgType: "int32"},
+		{TableName: "blog", Name: "uid", FieldName: "UID", SQLType: "INTEGER", ColumnType: "integer", GoType: "int32", ArgType: "int32"},
+		{TableName: "blog", Name: "title", FieldName: "Title", SQLType: "TEXT", ColumnType: "text", Comment: "标题", DefaultValue: "lower('a,b')",
+			Nullable: true, GoType: "string", ArgType: "string"},
+		{TableName: "blog", Name: "content", FieldName: "Content", SQLType: "TEXT", ColumnType: "text", Nullable: true, GoType: "string", ArgType: "string"},
+	}, blog.Columns)
+	assert.Equal(t, []Index{{Name: "idx_uid", Columns: []string{"uid"}}}, blog.Indexes)
+	assert.Equal(t, []Finder{
+		{Name: "ListByUID", Columns: []FinderParam{{Column: "uid", Field: "UID", Name: "uid", Type: "int32"}}},
+	}, blog.Finders)
+
+	tag := tables[2]
+	assert.False(t, tag.AutoIncrement)
+	assert.True(t, tag.Columns[0].IsPrimaryKey)
+	assert.True(t, tag.Columns[1].IsPrimaryKey)
+	assert.False(t, tag.Columns[1].Nullable)
+	assert.Equal(t, []Index{{Name: "PRIMARY", Unique: true, Primary: true, Columns: []string{"blog_id", "tag"}}}, tag.Indexes)
+	assert.Empty(t, tag.Finders)
+
+	// DEFAULT NULL 等同于没有默认值，INTEGER NOT NULL PRIMARY KEY 也是 rowid 的别名，BIGINT PRIMARY KEY 不是
+	tables, err = parseDDL(`
+create table comment (id integer not null primary key, remark varchar(32) default null, note text default 'NULL');
+create table token (id bigint primary key, token text)
+`)
+	assert.NoError(t, err)
+	assert.Len(t, tables, 2)
+	assert.True(t, tables[0].AutoIncrement)
+	assert.Equal(t, "auto_increment", tables[0].PrimaryKey.Extra)
+	assert.Empty(t, tables[0].Columns[1].DefaultValue)
+	assert.True(t, tables[0].Columns[1].Nullable)
+	assert.Equal(t, "NULL", tables[0].Columns[2].DefaultValue)
+	assert.False(t, tables[1].AutoIncrement)
+
+	_, err = parseDDL("create table t (name varchar(10) default 'abc)")
+	assert.Error(t, err)
+}
+
+func TestGenFromDDL(t *testing.T) {
+	dir := t.TempDir()
+	ddlFile := filepath.Join(dir, "schema.sql")
+	err := os.WriteFile(ddlFile, []byte("CREATE TABLE `user` (`id` bigint NOT NULL AUTO_INCREMENT, `name` varchar(32) NOT NULL COMMENT '用户名', PRIMARY KEY (`id`)) COMMENT='用户';"), 0600)
+	assert.NoError(t, err)
+	out := filepath.Join(dir, "out")
+	config := &Config{
+		DS: &DS{Type: "ddl", Dsn: dir},
+		Target: &ReverseTarget{
+			Custom: &Custom{OutDir: out, TagName: "json", Repo: true, GoPackage: "example.com/demo"},
+			Tables: map[string]TableConfig{"user": {}},
+		},
+	}
+	tables, err := loadTables(config)
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+	assert.NoError(t, gen(io.Discard, io.Discard, config, tables[0]))
+
+	entities, err := parseEntities(out, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, []EntityField{
+		{Name: "ID", Column: "id", GoType: "int64"},
+		{Name: "Name", Column: "name", GoType: "string"},
+	}, entities["User"])
+	assert.Empty(t, diffTable(tables[0], entities))
+	for _, name := range []string{"user.go", "user_test.go"} {
+		_, err = parser.ParseFile(token.NewFileSet(), filepath.Join(out, "repo", name), nil, 0)
+		assert.NoError(t, err)
+	}
+
+	config.Target.Tables = map[string]TableConfig{"not_exist": {}}
+	_, err = loadTables(config)
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/fengjx/daox/types"
+	"github.com/fengjx/daox/utils"
+)
+
+// diff 类型
+const (
+	DiffAdded         = "added"          // 数据库中新增的字段，实体中没有
+	DiffRemoved       = "removed"        // 数据库中已删除的字段，实体中还有
+	DiffTypeChanged   = "type-changed"   // 字段类型不一致
+	DiffMissingEntity = "missing-entity" // 找不到表对应的实体
+)
+
+// ColumnDiff 数据库表结构与实体的差异
+type ColumnDiff struct {
+	Table      string
+	Column     string
+	Kind       string
+	DBType     string // 数据库字段类型
+	GoType     string // 数据库字段类型对应的 go 类型
+	EntityType string // 实体中的字段类型
+}
+
+func (d ColumnDiff) String() string {
+	switch d.Kind {
+	case DiffMissingEntity:
+		return fmt.Sprintf("%s: %s", d.Table, d.Kind)
+	case DiffAdded:
+		return fmt.Sprintf("%s.%s: %s (%s %s)", d.Table, d.Column, d.Kind, d.DBType, d.GoType)
+	case DiffRemoved:
+		return fmt.Sprintf("%s.%s: %s (%s)", d.Table, d.Column, d.Kind, d.EntityType)
+	default:
+		return fmt.Sprintf("%s.%s: %s (%s %s -> %s)", d.Table, d.Column, d.Kind, d.DBType, d.GoType, d.EntityType)
+	}
+}
+
+// EntityField 实体字段
+type EntityField struct {
+	Name   string
+	Column string
+	GoType string
+}
+
+// diffCommand 对比数据库表结构和已生成的实体文件，有差异时返回非 0 退出码
+// eg: gen diff -f gen.yml --entity-dir ./out/entity
+func diffCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "diff",
+		Usage: "compare database schema with generated entity files",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "f",
+				Usage:    "config file path",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "entity-dir",
+				Usage: "generated entity files dir, default target.custom.out-dir",
+			},
+		},
+		Action: runDiff,
+	}
+}
+
+func runDiff(ctx *cli.Context) error {
+	bs, err := os.ReadFile(ctx.String("f"))
+	if err != nil {
+		return err
+	}
+	config := &Config{}
+	if err = yaml.Unmarshal(bs, config); err != nil {
+		return err
+	}
+	entityDir := ctx.String("entity-dir")
+	if entityDir == "" {
+		entityDir = config.Target.Custom.OutDir
+	}
+	tagName := config.Target.Custom.TagName
+	if tagName == "" {
+		tagName = "json"
+	}
+	entities, err := parseEntities(entityDir, tagName)
+	if err != nil {
+		return err
+	}
+	tables, err := loadTables(config)
+	if err != nil {
+		return err
+	}
+	var diffs []ColumnDiff
+	for _, table := range tables {
+		diffs = append(diffs, diffTable(table, entities)...)
+	}
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	if len(diffs) > 0 {
+		return cli.Exit(fmt.Sprintf("found %d schema differences", len(diffs)), 1)
+	}
+	fmt.Println("no differences found")
+	return nil
+}
+
+// diffTable 对比表结构与实体字段
+func diffTable(table *Table, entities map[string][]EntityField) []ColumnDiff {
+	fields, ok := entities[table.StructName]
+	if !ok {
+		return []ColumnDiff{{Table: table.Name, Kind: DiffMissingEntity}}
+	}
+	fieldMap := make(map[string]EntityField, len(fields))
+	for _, field := range fields {
+		fieldMap[field.Column] = field
+	}
+	var diffs []ColumnDiff
+	columnNames := make([]string, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		columnNames = append(columnNames, col.Name)
+		goType := col.GoType
+		if goType == "" {
+			goType = types.SQLType2GoTypeString(col.SQLType)
+		}
+		field, ok := fieldMap[col.Name]
+		if !ok {
+			diffs = append(diffs, ColumnDiff{
+				Table: table.Name, Column: col.Name, Kind: DiffAdded, DBType: col.SQLType, GoType: goType,
+			})
+			continue
+		}
+		if field.GoType != goType {
+			diffs = append(diffs, ColumnDiff{
+				Table: table.Name, Column: col.Name, Kind: DiffTypeChanged,
+				DBType: col.SQLType, GoType: goType, EntityType: field.GoType,
+			})
+		}
+	}
+	for _, field := range fields {
+		if !utils.ContainsString(columnNames, field.Column) {
+			diffs = append(diffs, ColumnDiff{
+				Table: table.Name, Column: field.Column, Kind: DiffRemoved, EntityType: field.GoType,
+			})
+		}
+	}
+	return diffs
+}
+
+// parseEntities 解析目录下所有 go 文件中的结构体
+// 返回结构体名称 -> 字段，字段名通过 tagName 获取，没有 tag 的字段忽略
+func parseEntities(dir string, tagName string) (map[string][]EntityField, error) {
+	entities := make(map[string][]EntityField)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			entities[spec.Name.Name] = structFields(st, tagName)
+			return false
+		})
+		return nil
+	})
+	return entities, err
+}
+
+func structFields(st *ast.StructType, tagName string) []EntityField {
+	var fields []EntityField
+	for _, field := range st.Fields.List {
+		if field.Tag == nil || len(field.Names) == 0 {
+			continue
+		}
+		tagValue, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		// 关联关系字段不是表字段
+		if _, ok := reflect.StructTag(tagValue).Lookup("rel"); ok {
+			continue
+		}
+		column, _, _ := strings.Cut(reflect.StructTag(tagValue).Get(tagName), ",")
+		if column == "" || column == "-" {
+			continue
+		}
+		fields = append(fields, EntityField{
+			Name:   field.Names[0].Name,
+			Column: column,
+			GoType: gotypes.ExprString(field.Type),
+		})
+	}
+	return fields
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTable(t *testing.T) {
+	table := newTable("user_info", "", []Column{
+		{Name: "id", SQLType: "BIGINT", IsPrimaryKey: true},
+		{Name: "nickname", SQLType: "VARCHAR"},
+		{Name: "ctime", SQLType: "DATETIME"},
+	}, nil)
+
+	bs, err := ReadFile("template/default/entity/{{.Table.Name}}.go.override.tmpl", true)
+	assert.NoError(t, err)
+	src, err := parse(string(bs), map[string]any{
+		"TagName": "json",
+		"Table":   table,
+	})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "user_info.go"), src, 0600))
+
+	entities, err := parseEntities(dir, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, []EntityField{
+		{Name: "ID", Column: "id", GoType: "int64"},
+		{Name: "Nickname", Column: "nickname", GoType: "string"},
+		{Name: "Ctime", Column: "ctime", GoType: "time.Time"},
+	}, entities["UserInfo"])
+	assert.Empty(t, diffTable(table, entities))
+
+	// 数据库表结构发生变化
+	table.Columns = []Column{
+		{Name: "id", SQLType: "BIGINT", IsPrimaryKey: true},
+		{Name: "nickname", SQLType: "INT"},
+		{Name: "sex", SQLType: "TINYINT"},
+	}
+	assert.Equal(t, []ColumnDiff{
+		{Table: "user_info", Column: "nickname", Kind: DiffTypeChanged, DBType: "INT", GoType: "int32", EntityType: "string"},
+		{Table: "user_info", Column: "sex", Kind: DiffAdded, DBType: "TINYINT", GoType: "int32"},
+		{Table: "user_info", Column: "ctime", Kind: DiffRemoved, EntityType: "time.Time"},
+	}, diffTable(table, entities))
+
+	table.StructName = "Blog"
+	assert.Equal(t, []ColumnDiff{{Table: "user_info", Kind: DiffMissingEntity}}, diffTable(table, entities))
+}
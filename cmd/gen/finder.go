@@ -0,0 +1,105 @@
+package main
+
+import (
+	"go/token"
+	"strings"
+
+	"github.com/fengjx/daox/utils"
+)
+
+// Index 表索引
+type Index struct {
+	Name    string
+	Unique  bool
+	Primary bool
+	Columns []string
+}
+
+// Finder 根据索引生成的查询方法
+// 唯一索引生成 GetByXxx，普通索引以及联合索引的最左前缀生成 ListByXxx
+type Finder struct {
+	Name    string // 方法名，eg: GetByUID、ListByUIDAndStatus
+	Unique  bool
+	Columns []FinderParam
+}
+
+// FinderParam 查询方法参数
+type FinderParam struct {
+	Column string // 字段名
+	Field  string // 结构体字段名
+	Name   string // 参数名
+	Type   string // 参数类型
+}
+
+// resolveFinders 根据索引生成查询方法，主键索引不生成
+func resolveFinders(table *Table) {
+	columnMap := make(map[string]Column, len(table.Columns))
+	for _, col := range table.Columns {
+		columnMap[col.Name] = col
+	}
+	table.Finders = nil
+	names := make(map[string]bool)
+	var argTypes, imports []string
+	for _, index := range table.Indexes {
+		if index.Primary {
+			continue
+		}
+		for n := 1; n <= len(index.Columns); n++ {
+			// 唯一索引的前缀不是唯一的，生成 ListByXxx
+			unique := index.Unique && n == len(index.Columns)
+			finder := Finder{Unique: unique}
+			var parts []string
+			ok := true
+			for _, name := range index.Columns[:n] {
+				col, exists := columnMap[name]
+				if !exists {
+					ok = false
+					break
+				}
+				parts = append(parts, col.FieldName)
+				finder.Columns = append(finder.Columns, FinderParam{
+					Column: col.Name,
+					Field:  col.FieldName,
+					Name:   paramName(col.Name),
+					Type:   col.ArgType,
+				})
+			}
+			if !ok {
+				break
+			}
+			if unique {
+				finder.Name = "GetBy" + strings.Join(parts, "And")
+			} else {
+				finder.Name = "ListBy" + strings.Join(parts, "And")
+			}
+			if names[finder.Name] {
+				continue
+			}
+			names[finder.Name] = true
+			table.Finders = append(table.Finders, finder)
+			for _, param := range finder.Columns {
+				argTypes = append(argTypes, param.Type)
+				imports = append(imports, columnMap[param.Column].Imports...)
+			}
+		}
+	}
+	table.RepoGoImports = goImports(argTypes, imports)
+}
+
+// paramName 字段名转换成参数名，eg: user_id -> userID，type -> typeVal
+func paramName(column string) string {
+	name := utils.GonicCase(column)
+	// 首字母缩写整体转小写，eg: ID -> id，UID -> uid
+	i := 0
+	for i < len(name) && name[i] >= 'A' && name[i] <= 'Z' {
+		i++
+	}
+	if i > 1 && i < len(name) {
+		i--
+	}
+	name = strings.ToLower(name[:i]) + name[i:]
+	if token.IsKeyword(name) {
+		name += "Val"
+	}
+	return name
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveFinders(t *testing.T) {
+	table := &Table{
+		Columns: []Column{
+			{Name: "id", FieldName: "ID", ArgType: "int64"},
+			{Name: "uid", FieldName: "UID", ArgType: "int64"},
+			{Name: "type", FieldName: "Type", ArgType: "int32"},
+			{Name: "ctime", FieldName: "Ctime", ArgType: "time.Time", Imports: []string{"time"}},
+		},
+		Indexes: []Index{
+			{Name: "PRIMARY", Primary: true, Unique: true, Columns: []string{"id"}},
+			{Name: "uni_uid_type", Unique: true, Columns: []string{"uid", "type"}},
+			{Name: "idx_uid", Columns: []string{"uid"}},
+			{Name: "idx_ctime", Columns: []string{"ctime"}},
+			{Name: "idx_not_exist", Columns: []string{"not_exist"}},
+		},
+	}
+	resolveFinders(table)
+	assert.Equal(t, []Finder{
+		{Name: "ListByUID", Columns: []FinderParam{{Column: "uid", Field: "UID", Name: "uid", Type: "int64"}}},
+		{Name: "GetByUIDAndType", Unique: true, Columns: []FinderParam{
+			{Column: "uid", Field: "UID", Name: "uid", Type: "int64"},
+			{Column: "type", Field: "Type", Name: "typeVal", Type: "int32"},
+		}},
+		{Name: "ListByCtime", Columns: []FinderParam{{Column: "ctime", Field: "Ctime", Name: "ctime", Type: "time.Time"}}},
+	}, table.Finders)
+	assert.Equal(t, []string{"time"}, table.RepoGoImports)
+}
+
+func TestParamName(t *testing.T) {
+	assert.Equal(t, "id", paramName("id"))
+	assert.Equal(t, "userID", paramName("user_id"))
+	assert.Equal(t, "uidList", paramName("uid_list"))
+	assert.Equal(t, "funcVal", paramName("func"))
+}
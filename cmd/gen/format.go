@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 用户代码区域标记，重新生成文件时保留区域中的内容
+// eg:
+//
+//	// user-code:begin methods
+//	func (m *User) IsAdmin() bool { return m.Role == "admin" }
+//	// user-code:end methods
+const (
+	regionBegin = "// user-code:begin "
+	regionEnd   = "// user-code:end "
+)
+
+// prepareFile 读取已存在的文件，合并用户代码区域并格式化 go 文件
+func prepareFile(file *genFile) error {
+	old, err := os.ReadFile(file.Path)
+	switch {
+	case err == nil:
+		file.Old = old
+		file.Exists = true
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	if file.skip() {
+		return nil
+	}
+	if file.Exists {
+		content, orphans, err := mergeRegions(file.Content, file.Old)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Path, err)
+		}
+		file.Content = content
+		for _, name := range orphans {
+			file.Warnings = append(file.Warnings, fmt.Sprintf("user code region %q not found in template, appended to the end of file", name))
+		}
+	}
+	if filepath.Ext(file.Path) == ".go" {
+		content, err := format.Source(file.Content)
+		if err != nil {
+			return fmt.Errorf("format %s: %w\n%s", file.Path, err, numberLines(file.Content))
+		}
+		file.Content = content
+	}
+	return nil
+}
+
+// region 用户代码区域
+type region struct {
+	name  string
+	lines []string
+}
+
+// parseRegions 解析用户代码区域
+func parseRegions(content []byte) (map[string]region, error) {
+	regions := make(map[string]region)
+	var current *region
+	for i, line := range strings.Split(string(content), "\n") {
+		text := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(text, regionBegin):
+			if current != nil {
+				return nil, fmt.Errorf("line %d: nested user code region %q", i+1, current.name)
+			}
+			name := strings.TrimSpace(strings.TrimPrefix(text, regionBegin))
+			if _, ok := regions[name]; ok {
+				return nil, fmt.Errorf("line %d: duplicate user code region %q", i+1, name)
+			}
+			current = &region{name: name}
+		case strings.HasPrefix(text, regionEnd):
+			name := strings.TrimSpace(strings.TrimPrefix(text, regionEnd))
+			if current == nil || current.name != name {
+				return nil, fmt.Errorf("line %d: unexpected end of user code region %q", i+1, name)
+			}
+			regions[name] = *current
+			current = nil
+		case current != nil:
+			current.lines = append(current.lines, line)
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("user code region %q not closed", current.name)
+	}
+	return regions, nil
+}
+
+// mergeRegions 将已存在文件中用户代码区域的内容替换到新生成的内容中
+// 已存在文件中有内容的区域在模板中不存在时，连同区域标记追加到文件末尾，避免用户代码丢失，返回这些区域的名称
+func mergeRegions(content []byte, old []byte) ([]byte, []string, error) {
+	oldRegions, err := parseRegions(old)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(oldRegions) == 0 {
+		return content, nil, nil
+	}
+	if _, err = parseRegions(content); err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	used := make(map[string]bool)
+	skip := false
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		text := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(text, regionBegin):
+			buf.WriteString(line)
+			name := strings.TrimSpace(strings.TrimPrefix(text, regionBegin))
+			if r, ok := oldRegions[name]; ok {
+				used[name] = true
+				skip = true
+				for _, l := range r.lines {
+					buf.WriteString(l)
+					buf.WriteByte('\n')
+				}
+			}
+			continue
+		case strings.HasPrefix(text, regionEnd):
+			skip = false
+		case skip:
+			continue
+		}
+		buf.WriteString(line)
+	}
+	var orphans []string
+	for _, name := range sortedRegionNames(oldRegions) {
+		r := oldRegions[name]
+		if used[name] || strings.TrimSpace(strings.Join(r.lines, "")) == "" {
+			continue
+		}
+		orphans = append(orphans, name)
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString("\n" + regionBegin + name + "\n")
+		for _, l := range r.lines {
+			buf.WriteString(l)
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(regionEnd + name + "\n")
+	}
+	return buf.Bytes(), orphans, nil
+}
+
+func sortedRegionNames(regions map[string]region) []string {
+	names := make([]string, 0, len(regions))
+	for name := range regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// numberLines 给内容加上行号，方便定位格式化错误
+func numberLines(content []byte) string {
+	var buf strings.Builder
+	for i, line := range strings.Split(string(content), "\n") {
+		fmt.Fprintf(&buf, "%4d  %s\n", i+1, line)
+	}
+	return buf.String()
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeRegions(t *testing.T) {
+	content := []byte(`package demo
+
+// user-code:begin imports
+// user-code:end imports
+
+func A() {}
+
+// user-code:begin methods
+func B() {}
+// user-code:end methods
+`)
+	old := []byte(`package demo
+
+// user-code:begin methods
+func C() {}
+// user-code:end methods
+`)
+	merged, orphans, err := mergeRegions(content, old)
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+	assert.Equal(t, `package demo
+
+// user-code:begin imports
+// user-code:end imports
+
+func A() {}
+
+// user-code:begin methods
+func C() {}
+// user-code:end methods
+`, string(merged))
+
+	// 模板中不存在的区域追加到文件末尾
+	merged, orphans, err = mergeRegions([]byte("package demo\n"), old)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"methods"}, orphans)
+	assert.Equal(t, "package demo\n\n// user-code:begin methods\nfunc C() {}\n// user-code:end methods\n", string(merged))
+
+	_, _, err = mergeRegions(content, []byte("// user-code:begin a\n// user-code:begin b\n"))
+	assert.Error(t, err)
+	_, _, err = mergeRegions(content, []byte("// user-code:begin a\n"))
+	assert.Error(t, err)
+}
+
+func TestGenFormatAndCheck(t *testing.T) {
+	dir := t.TempDir()
+	tmplDir := filepath.Join(dir, "template")
+	assert.NoError(t, os.MkdirAll(filepath.Join(tmplDir, "model"), 0755))
+	tmpl := "package model\n\ntype {{.Table.StructName}} struct {\n    ID int64\n}\n\n// user-code:begin methods\n// user-code:end methods\n"
+	tmplFile := filepath.Join(tmplDir, "model", "{{.Table.Name}}.go.override.tmpl")
+	assert.NoError(t, os.WriteFile(tmplFile, []byte(tmpl), 0600))
+	out := filepath.Join(dir, "out")
+	config := &Config{
+		Target: &ReverseTarget{
+			Custom: &Custom{OutDir: out, TemplateDir: tmplDir, FileMode: "0640"},
+		},
+	}
+	table := newTable("user", "", []Column{{Name: "id", SQLType: "BIGINT", IsPrimaryKey: true}}, nil)
+
+	var buf bytes.Buffer
+	stale, err := check(&buf, config, table)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stale)
+	assert.Contains(t, buf.String(), "missing")
+
+	// 写入的文件路径输出到 w
+	buf.Reset()
+	assert.NoError(t, gen(&buf, io.Discard, config, table))
+	target := filepath.Join(out, "model", "user.go")
+	assert.Equal(t, target+"\n", buf.String())
+	bs, err := os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bs), "type User struct {\n\tID int64\n}")
+	info, err := os.Stat(target)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// 用户代码区域在重新生成后保留
+	userCode := bytes.Replace(bs, []byte("// user-code:end methods"), []byte("func (u *User) Name() string { return \"u\" }\n\n// user-code:end methods"), 1)
+	assert.NoError(t, os.WriteFile(target, userCode, 0600))
+	buf.Reset()
+	stale, err = check(&buf, config, table)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stale)
+	assert.NoError(t, gen(io.Discard, io.Discard, config, table))
+	bs, err = os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bs), "func (u *User) Name() string")
+
+	assert.NoError(t, os.WriteFile(tmplFile, []byte(tmpl+"var X = 1\n"), 0600))
+	buf.Reset()
+	stale, err = check(&buf, config, table)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stale)
+	assert.Contains(t, buf.String(), "stale")
+
+	// 语法错误
+	assert.NoError(t, os.WriteFile(tmplFile, []byte("package model\n\nfunc {\n// user-code:begin methods\n// user-code:end methods\n"), 0600))
+	assert.ErrorContains(t, gen(io.Discard, io.Discard, config, table), "format")
+
+	config.Target.Custom.FileMode = "abc"
+	assert.Error(t, gen(io.Discard, io.Discard, config, table))
+}
+
+func TestGenBuiltinTemplateRegions(t *testing.T) {
+	out := t.TempDir()
+	config := &Config{
+		Target: &ReverseTarget{
+			Custom: &Custom{OutDir: out, TagName: "json", GoPackage: "github.com/demo", Repo: true},
+		},
+	}
+	table := newTable("user", "用户", []Column{
+		{Name: "id", SQLType: "BIGINT", IsPrimaryKey: true},
+		{Name: "name", SQLType: "VARCHAR"},
+	}, nil)
+	assert.NoError(t, gen(io.Discard, io.Discard, config, table))
+
+	files := map[string]string{
+		filepath.Join(out, "entity", "user.go"): "func (m *User) DisplayName() string { return strings.ToUpper(m.Name) }",
+		filepath.Join(out, "meta", "user.go"):   "func (m UserM) Alias() string { return \"u\" }",
+		filepath.Join(out, "repo", "user.go"):   "func (r *UserRepo) Hello() string { return \"hello\" }",
+	}
+	for file, code := range files {
+		bs, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		edited := strings.Replace(string(bs), "// user-code:end methods", code+"\n\n// user-code:end methods", 1)
+		if strings.HasSuffix(file, filepath.Join("entity", "user.go")) {
+			edited = strings.Replace(edited, "// user-code:end imports", "import \"strings\"\n// user-code:end imports", 1)
+		}
+		assert.NotEqual(t, string(bs), edited, file)
+		assert.NoError(t, os.WriteFile(file, []byte(edited), 0644))
+	}
+
+	// 表结构变化后重新生成，用户代码保留
+	table = newTable("user", "用户", []Column{
+		{Name: "id", SQLType: "BIGINT", IsPrimaryKey: true},
+		{Name: "name", SQLType: "VARCHAR"},
+		{Name: "age", SQLType: "INT"},
+	}, nil)
+	var buf bytes.Buffer
+	stale, err := check(&buf, config, table)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stale)
+	assert.NoError(t, gen(io.Discard, io.Discard, config, table))
+	for file, code := range files {
+		bs, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Contains(t, string(bs), code, file)
+		if !strings.Contains(file, "repo") {
+			assert.Contains(t, string(bs), "Age", file)
+		}
+	}
+	bs, err := os.ReadFile(filepath.Join(out, "entity", "user.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bs), "import \"strings\"")
+	stale, err = check(&buf, config, table)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stale)
+}
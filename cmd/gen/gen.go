@@ -3,19 +3,17 @@ package main
 import (
 	"bytes"
 	"embed"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
-	"time"
 
-	"github.com/go-sql-driver/mysql"
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/jmoiron/sqlx"
-	"github.com/jmoiron/sqlx/reflectx"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
 
@@ -33,12 +31,28 @@ func main() {
 		Version:     "1.0.0",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "f",
-				Usage:    "config file path",
-				Required: true,
+				Name:  "f",
+				Usage: "config file path",
+			},
+			&cli.StringSliceFlag{
+				Name:  "table",
+				Usage: "only generate the specified tables, support glob pattern, eg: --table user --table 't_*'",
+			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "report stale generated files without writing, exit non-zero when found",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "print rendered output and diff against existing files instead of writing",
 			},
 		},
 		Action: run,
+		Commands: []*cli.Command{
+			migrateCommand(),
+			diffCommand(),
+			importCommand(),
+		},
 	}
 	if err := app.Run(os.Args); err != nil {
 		log.Fatal(err)
@@ -47,6 +61,9 @@ func main() {
 
 func run(ctx *cli.Context) error {
 	configFile := ctx.String("f")
+	if configFile == "" {
+		return errors.New(`Required flag "f" not set`)
+	}
 	bs, err := os.ReadFile(configFile)
 	if err != nil {
 		return err
@@ -55,131 +72,121 @@ func run(ctx *cli.Context) error {
 	if err = yaml.Unmarshal(bs, config); err != nil {
 		return err
 	}
-	if config.DS.Type != "mysql" {
-		fmt.Println("only support mysql now")
-		return nil
+	if config.Target.Custom.Repo && config.Target.Custom.GoPackage == "" {
+		return errors.New("target.custom.go-package is required when repo is enabled")
 	}
-	dsnCfg, err := mysql.ParseDSN(config.DS.Dsn)
+	tables, err := loadTables(config, ctx.StringSlice("table")...)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	db := sqlx.MustOpen(config.DS.Type, config.DS.Dsn)
-	db.Mapper = reflectx.NewMapperFunc("db", strings.ToTitle)
-	err = db.Ping()
-	if err != nil {
-		log.Fatal(err)
+	if ctx.Bool("check") {
+		stale := 0
+		for _, table := range tables {
+			n, err := check(ctx.App.Writer, config, table)
+			if err != nil {
+				return err
+			}
+			stale += n
+		}
+		if stale > 0 {
+			return cli.Exit(fmt.Sprintf("found %d stale generated files", stale), 1)
+		}
+		return nil
+	}
+	// dry-run 时标准输出只有渲染结果，表名输出到标准错误
+	status := ctx.App.Writer
+	if ctx.Bool("dry-run") {
+		status = ctx.App.ErrWriter
 	}
-	for tableName := range config.Target.Tables {
-		table := loadTableMeta(db, dsnCfg.DBName, tableName)
-		fmt.Println(table.Name, table.Comment)
-		gen(config, table)
+	for _, table := range tables {
+		fmt.Fprintln(status, table.Name, table.Comment)
+		if ctx.Bool("dry-run") {
+			err = dryRun(ctx.App.Writer, config, table)
+		} else {
+			err = gen(ctx.App.Writer, ctx.App.ErrWriter, config, table)
+		}
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func loadTableMeta(db *sqlx.DB, dbName, tableName string) *Table {
-	args := []any{dbName, tableName}
-	querySQL := "SELECT `TABLE_NAME`, `ENGINE`, `AUTO_INCREMENT`, `TABLE_COMMENT` from" +
-		" `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA`=? AND TABLE_NAME = ?" +
-		" AND (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB')"
+// genFile 模板渲染后的文件
+type genFile struct {
+	Path     string
+	Content  []byte
+	Old      []byte   // 已存在的文件内容
+	Exists   bool     // 文件是否已存在
+	Override bool     // 文件已存在时重新生成，保留用户代码区域
+	Warnings []string // 重新生成时的警告，eg: 模板中不存在的用户代码区域
+}
+
+// skip 文件已存在并且不需要重新生成
+func (f genFile) skip() bool {
+	return f.Exists && !f.Override
+}
 
-	rows, err := db.Query(querySQL, args...)
+// gen 生成表对应的文件，写入的文件路径输出到 w，警告输出到 errW
+func gen(w, errW io.Writer, config *Config, table *Table) error {
+	mode, err := config.Target.Custom.fileMode()
 	if err != nil {
-		log.Fatal(err)
-	}
-	defer rows.Close()
-	table := new(Table)
-	for rows.Next() {
-		var name, engine string
-		var comment *string
-		var autoIncr *int
-		err = rows.Scan(&name, &engine, &autoIncr, &comment)
-		if err != nil {
-			log.Fatal(err)
-		}
-		table.Name = name
-		table.StructName = utils.GonicCase(name)
-		if comment != nil {
-			table.Comment = *comment
-		}
-		table.StoreEngine = engine
-		if autoIncr != nil {
-			table.AutoIncrement = true
-		}
+		return err
 	}
-	if rows.Err() != nil {
-		log.Fatal(err)
+	files, err := renderTable(config, table)
+	if err != nil {
+		return err
 	}
-	columns, primaryKey := loadColumnMeta(db, dbName, tableName)
-	table.Columns = columns
-	table.PrimaryKey = primaryKey
-	table.GoImports = GenGoImports(table.Columns)
-	return table
+	return writeFiles(w, errW, files, mode)
 }
 
-// loadColumnMeta
-// []*Column table column meta
-// *Column PrimaryKey column
-func loadColumnMeta(db *sqlx.DB, dbName, tableName string) ([]Column, Column) {
-	args := []any{dbName, tableName}
-	querySQL := `SELECT
-			column_name,
-			column_type,
-			column_comment, 
-			column_key,
-			ifnull(column_default, ''),
-			ifnull(extra, '')
-		FROM information_schema.columns
-		WHERE table_schema = ? AND table_name = ? ORDER BY ORDINAL_POSITION`
-	rows, err := db.Query(querySQL, args...)
+// check 检查生成的文件是否与模板渲染结果一致，返回过期的文件数量
+// 只检查重新生成的文件，只生成一次的文件不检查
+func check(w io.Writer, config *Config, table *Table) (int, error) {
+	files, err := renderTable(config, table)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
 	}
-	defer rows.Close()
-	var columns []Column
-	var primaryKey Column
-	for rows.Next() {
-		var columnName string
-		var columnType string
-		var columnComment string
-		var columnKey string
-		var columnDefault string
-		var extra string
-		err = rows.Scan(
-			&columnName,
-			&columnType,
-			&columnComment,
-			&columnKey,
-			&columnDefault,
-			&extra,
-		)
-		if err != nil {
-			log.Fatal(err)
+	stale := 0
+	for _, file := range files {
+		switch {
+		case !file.Exists:
+			if file.Override {
+				fmt.Fprintf(w, "%s: missing\n", file.Path)
+				stale++
+			}
+		case file.Override && !bytes.Equal(file.Old, file.Content):
+			fmt.Fprintf(w, "%s: stale\n", file.Path)
+			stale++
 		}
-		col := Column{}
-		col.Name = strings.Trim(columnName, "` ")
-		col.Comment = columnComment
-		col.DefaultValue = columnDefault
-		col.Extra = extra
-
-		fields := strings.Fields(columnType)
-		columnType = fields[0]
-		cts := strings.Split(columnType, "(")
-		colName := cts[0]
-		// Remove the /* mariadb-5.3 */ suffix from coltypes
-		colName = strings.TrimSuffix(colName, "/* mariadb-5.3 */")
-		col.SQLType = strings.ToUpper(colName)
+	}
+	return stale, nil
+}
 
-		if columnKey == "PRI" {
-			col.IsPrimaryKey = true
-			primaryKey = col
+// dryRun 只输出渲染结果，不写文件
+// 新文件输出完整内容，已存在的文件输出与现有内容的 diff
+func dryRun(w io.Writer, config *Config, table *Table) error {
+	files, err := renderTable(config, table)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		switch {
+		case !file.Exists:
+			fmt.Fprintf(w, "==> %s (new)\n%s\n", file.Path, file.Content)
+		case file.skip():
+			fmt.Fprintf(w, "==> %s (exists, skip)\n", file.Path)
+		case bytes.Equal(file.Old, file.Content):
+			fmt.Fprintf(w, "==> %s (unchanged)\n", file.Path)
+		default:
+			fmt.Fprintf(w, "==> %s (changed)\n%s", file.Path, unifiedDiff(file.Path, file.Old, file.Content))
 		}
-		columns = append(columns, col)
 	}
-	return columns, primaryKey
+	return nil
 }
 
-func gen(config *Config, table *Table) {
+// renderTable 渲染表对应的所有模板文件
+func renderTable(config *Config, table *Table) ([]genFile, error) {
 	dir := "template/default"
 	isEmbed := true
 	if config.Target.Custom.TemplateDir != "" {
@@ -188,95 +195,129 @@ func gen(config *Config, table *Table) {
 	}
 	entries, err := ReadDir(dir, isEmbed)
 	if err != nil {
-		fmt.Println(err.Error())
-		return
+		return nil, err
 	}
 	attr := map[string]any{
-		"Var":      config.Target.Custom.Var,
-		"TagName":  config.Target.Custom.TagName,
-		"Table":    table,
-		"TableOpt": config.Target.Tables[table.Name],
+		"Var":       config.Target.Custom.Var,
+		"TagName":   config.Target.Custom.TagName,
+		"GoPackage": config.Target.Custom.GoPackage,
+		"Table":     table,
+		"TableOpt":  config.Target.Tables[table.Name],
 	}
 	out := filepath.Join(config.Target.Custom.OutDir)
-	render(isEmbed, filepath.Join(dir), "", entries, out, attr)
+	files, err := render(isEmbed, filepath.Join(dir), "", entries, out, attr)
+	if err != nil {
+		return nil, err
+	}
+	if config.Target.Custom.Repo {
+		repoDir := "template/repo"
+		entries, err = ReadDir(repoDir, true)
+		if err != nil {
+			return nil, err
+		}
+		repoFiles, err := render(true, repoDir, "", entries, out, attr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, repoFiles...)
+	}
+	for i := range files {
+		if err = prepareFile(&files[i]); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
 }
 
-// render 递归生成文件
-func render(isEmbed bool, basePath string, parent string, entries []os.DirEntry, outDir string, attr map[string]any) {
+// render 递归渲染模板文件
+func render(isEmbed bool, basePath string, parent string, entries []os.DirEntry, outDir string, attr map[string]any) ([]genFile, error) {
 	if parent == "" {
 		parent = basePath
 	}
+	var files []genFile
 	for _, entry := range entries {
 		path := filepath.Join(parent, entry.Name())
 		if entry.IsDir() {
 			children, err := ReadDir(filepath.Join(parent, entry.Name()), isEmbed)
 			if err != nil {
-				fmt.Println(err.Error())
-				return
+				return nil, err
 			}
-			render(isEmbed, basePath, path, children, outDir, attr)
+			childFiles, err := render(isEmbed, basePath, path, children, outDir, attr)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, childFiles...)
 			continue
 		}
 		targetDirBys, err := parse(strings.ReplaceAll(parent, basePath, ""), attr)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		targetDir := filepath.Join(outDir, string(targetDirBys))
-		err = os.MkdirAll(targetDir, 0755)
-		if err != nil {
-			log.Fatal(err)
-		}
 		suffix := ""
 		override := false
-		re := false
 		if strings.HasSuffix(entry.Name(), ".override.tmpl") {
 			suffix = ".override.tmpl"
 			override = true
 		} else if strings.HasSuffix(entry.Name(), ".re.tmpl") {
-			suffix = "re..tmpl"
-			re = true
+			// 兼容旧版本，与 .override.tmpl 相同
+			suffix = ".re.tmpl"
+			override = true
 		} else if strings.HasSuffix(entry.Name(), ".tmpl") {
 			suffix = ".tmpl"
 		}
+		bs, err := ReadFile(path, isEmbed)
+		if err != nil {
+			return nil, err
+		}
 		if suffix == "" {
-			targetFile := filepath.Join(targetDir, entry.Name())
-			fmt.Println(targetFile)
 			// 其他不需要渲染的文件直接复制
-			err = utils.CopyFile(path, targetFile)
-			if err != nil {
-				fmt.Println(err.Error())
-				return
-			}
+			files = append(files, genFile{
+				Path:     filepath.Join(targetDir, entry.Name()),
+				Content:  bs,
+				Override: true,
+			})
 			continue
 		}
 		filenameBys, err := parse(strings.ReplaceAll(entry.Name(), suffix, ""), attr)
 		if err != nil {
-			log.Fatal(err)
-		}
-		targetFile := filepath.Join(targetDir, string(filenameBys))
-		if _, err = os.Stat(targetFile); !override && err == nil {
-			if !re {
-				continue
-			}
-			targetFile = fmt.Sprintf("%s.%d", targetFile, time.Now().Unix())
-		}
-		fmt.Println(targetFile)
-		bs, err := ReadFile(path, isEmbed)
-		if err != nil {
-			fmt.Println(err.Error())
-			return
+			return nil, err
 		}
 		newbytes, err := parse(string(bs), attr)
 		if err != nil {
-			fmt.Println(err.Error())
-			return
+			return nil, fmt.Errorf("%s: %w", path, err)
 		}
-		err = os.WriteFile(targetFile, newbytes, 0600)
-		if err != nil {
-			fmt.Println(err.Error())
-			return
+		files = append(files, genFile{
+			Path:     filepath.Join(targetDir, string(filenameBys)),
+			Content:  newbytes,
+			Override: override,
+		})
+	}
+	return files, nil
+}
+
+// writeFiles 写入渲染后的文件，内容没有变化的文件不会重写
+func writeFiles(w, errW io.Writer, files []genFile, mode os.FileMode) error {
+	for _, file := range files {
+		if file.skip() || (file.Exists && bytes.Equal(file.Old, file.Content)) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0755); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, file.Path)
+		for _, warning := range file.Warnings {
+			fmt.Fprintf(errW, "warning: %s: %s\n", file.Path, warning)
+		}
+		if err := os.WriteFile(file.Path, file.Content, mode); err != nil {
+			return err
+		}
+		// WriteFile 不会修改已存在文件的权限
+		if err := os.Chmod(file.Path, mode); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 func parse(text string, attr map[string]any) ([]byte, error) {
@@ -291,6 +332,7 @@ func parse(text string, attr map[string]any) ([]byte, error) {
 		"Add":                  utils.Add,
 		"Sub":                  utils.Sub,
 		"SQLType2GoTypeString": types.SQLType2GoTypeString,
+		"Join":                 strings.Join,
 	}
 	t, err := template.New("").Funcs(funcMap).Parse(text)
 	if err != nil {
@@ -317,14 +359,19 @@ type DS struct {
 type Var map[string]string
 
 type TableConfig struct {
-	Module string `yaml:"module"`
-	IsTime bool   `yaml:"is-time"`
-	Var    Var    `yaml:"var"`
+	Module     string                  `yaml:"module"`
+	IsTime     bool                    `yaml:"is-time"`
+	Var        Var                     `yaml:"var"`
+	Columns    map[string]TypeOverride `yaml:"columns"`     // 按字段名自定义类型
+	StructName string                  `yaml:"struct-name"` // 自定义结构体名称
+	Rename     map[string]string       `yaml:"rename"`      // 按字段名自定义结构体字段名称
 }
 
 type ReverseTarget struct {
-	Custom *Custom
-	Tables map[string]TableConfig
+	Custom  *Custom
+	Tables  map[string]TableConfig
+	Include []string `yaml:"include"` // 需要生成的表名通配符，eg: t_*
+	Exclude []string `yaml:"exclude"` // 需要排除的表名通配符，eg: *_bak
 }
 
 type Custom struct {
@@ -332,6 +379,30 @@ type Custom struct {
 	OutDir      string `yaml:"out-dir"`
 	Var         Var    `yaml:"var"`
 	TagName     string `yaml:"tag-name"`
+	// NullType 可以为 NULL 的字段生成的类型，sql: sql.Null*，pointer: 指针，默认不处理
+	NullType string `yaml:"null-type"`
+	// Types 按 sql 类型自定义类型，eg: JSON、DECIMAL
+	Types map[string]TypeOverride `yaml:"types"`
+	// Repo 是否生成 repo 和测试脚手架
+	Repo bool `yaml:"repo"`
+	// GoPackage out-dir 对应的 go 包路径，生成 repo 时需要引用 entity 和 meta
+	GoPackage string `yaml:"go-package"`
+	// TablePrefix 生成结构体名称时去掉的表名前缀，eg: t_
+	TablePrefix []string `yaml:"table-prefix"`
+	// FileMode 生成文件的权限，八进制字符串，默认 0644
+	FileMode string `yaml:"file-mode"`
+}
+
+// fileMode 解析生成文件的权限
+func (c *Custom) fileMode() (os.FileMode, error) {
+	if c.FileMode == "" {
+		return 0644, nil
+	}
+	mode, err := strconv.ParseUint(c.FileMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid file-mode: %s", c.FileMode)
+	}
+	return os.FileMode(mode), nil
 }
 
 // Table represents a database table
@@ -343,31 +414,47 @@ type Table struct {
 	AutoIncrement bool
 	Comment       string
 	StoreEngine   string
-	GoImports     []string
+	GoImports     []string // 实体需要的 import
+	MetaGoImports []string // meta 需要的 import
+	Indexes       []Index  // 索引
+	Finders       []Finder // 根据索引生成的查询方法
+	RepoGoImports []string // repo 需要的 import
 }
 
 type Column struct {
 	TableName    string
 	Name         string
-	SQLType      string
+	FieldName    string // 结构体字段名称
+	SQLType      string // 不包含参数的类型，eg: VARCHAR
+	ColumnType   string // 完整的字段类型，eg: varchar(32)、decimal(10,2) unsigned
 	Comment      string
 	IsPrimaryKey bool
 	DefaultValue string
 	Extra        string
+	Nullable     bool     // 是否可以为 NULL
+	Unsigned     bool     // 是否无符号
+	Length       int      // 字段长度
+	Precision    int      // 数值精度
+	Scale        int      // 小数位数
+	EnumValues   []string // enum、set 类型的可选值
+	GoType       string   // 实体字段的 go 类型
+	ArgType      string   // 查询参数的 go 类型，不包含 NULL 处理
+	Imports      []string // 自定义类型需要的 import
 }
 
+// GenGoImports 根据字段的 go 类型生成 import 列表
 func GenGoImports(cols []Column) []string {
-	imports := make(map[string]string)
-	results := make([]string, 0)
+	goTypes := make([]string, 0, len(cols))
+	var extra []string
 	for _, col := range cols {
-		if types.SQLType2GolangType(col.SQLType) == types.TimeType {
-			if _, ok := imports["time"]; !ok {
-				imports["time"] = "time"
-				results = append(results, "time")
-			}
+		goType := col.GoType
+		if goType == "" {
+			goType = types.SQLType2GoTypeString(col.SQLType)
 		}
+		goTypes = append(goTypes, goType)
+		extra = append(extra, col.Imports...)
 	}
-	return results
+	return goImports(goTypes, extra)
 }
 
 func ReadDir(name string, isEmbed bool) ([]fs.DirEntry, error) {
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fengjx/daox/types"
+)
+
+// null-type 配置，可以为 NULL 的字段生成的类型
+const (
+	NullTypeNone    = ""        // 不处理，与非空字段类型一致
+	NullTypeSQL     = "sql"     // sql.NullString、sql.NullInt64 等
+	NullTypePointer = "pointer" // *string、*int64 等
+)
+
+// TypeOverride 自定义字段类型
+type TypeOverride struct {
+	Type   string `yaml:"type"`   // go 类型，eg: decimal.Decimal
+	Import string `yaml:"import"` // 类型所在的包，eg: github.com/shopspring/decimal
+}
+
+// sqlNullTypes 有对应 sql.Null* 类型的 go 类型
+var sqlNullTypes = map[string]string{
+	"string":    "sql.NullString",
+	"int64":     "sql.NullInt64",
+	"int32":     "sql.NullInt32",
+	"int16":     "sql.NullInt16",
+	"uint8":     "sql.NullByte",
+	"float64":   "sql.NullFloat64",
+	"bool":      "sql.NullBool",
+	"time.Time": "sql.NullTime",
+}
+
+// unsignedTypes 无符号整数类型
+var unsignedTypes = map[string]string{
+	types.Bit:       "uint8",
+	types.TinyInt:   "uint8",
+	types.SmallInt:  "uint16",
+	types.MediumInt: "uint32",
+	types.Int:       "uint32",
+	types.Integer:   "uint32",
+	types.BigInt:    "uint64",
+}
+
+// parseColumnType 解析完整的字段类型，设置长度、精度、是否无符号、枚举值
+// eg: varchar(32)、decimal(10,2)、int(11) unsigned、enum('a','b')
+func parseColumnType(col *Column, columnType string) {
+	col.ColumnType = strings.TrimSpace(columnType)
+	col.Unsigned = strings.Contains(strings.ToLower(columnType), "unsigned")
+	start := strings.Index(columnType, "(")
+	end := strings.LastIndex(columnType, ")")
+	if start < 0 || end <= start {
+		return
+	}
+	params := columnType[start+1 : end]
+	switch col.SQLType {
+	case types.Enum, types.Set:
+		tokens, err := tokenize(params)
+		if err != nil {
+			return
+		}
+		for _, tok := range tokens {
+			if tok.kind == tokenString {
+				col.EnumValues = append(col.EnumValues, tok.text)
+			}
+		}
+	case types.Decimal, types.Numeric, types.Float, types.Double, types.Real:
+		precision, scale, _ := strings.Cut(params, ",")
+		col.Precision, _ = strconv.Atoi(strings.TrimSpace(precision))
+		col.Scale, _ = strconv.Atoi(strings.TrimSpace(scale))
+	default:
+		col.Length, _ = strconv.Atoi(strings.TrimSpace(params))
+	}
+}
+
+// resolveGoTypes 设置字段的 go 类型和表需要的 import
+// 优先级：表字段配置 > sql 类型配置 > 默认类型
+func resolveGoTypes(table *Table, custom *Custom, tableConfig TableConfig) {
+	var nullType string
+	var typeMap map[string]TypeOverride
+	if custom != nil {
+		nullType = custom.NullType
+		typeMap = custom.Types
+	}
+	var argTypes, imports []string
+	for i := range table.Columns {
+		col := &table.Columns[i]
+		col.Imports = nil
+		override, ok := tableConfig.Columns[col.Name]
+		if !ok {
+			override, ok = typeMap[col.SQLType]
+		}
+		if ok && override.Type != "" {
+			// 自定义类型需要自己处理 NULL
+			col.GoType = override.Type
+			col.ArgType = override.Type
+			if override.Import != "" {
+				col.Imports = []string{override.Import}
+				imports = append(imports, override.Import)
+			}
+		} else {
+			col.ArgType = baseGoType(col)
+			col.GoType = col.ArgType
+			if col.Nullable && !col.IsPrimaryKey {
+				col.GoType = nullableGoType(col.ArgType, nullType)
+			}
+		}
+		argTypes = append(argTypes, col.ArgType)
+	}
+	table.GoImports = GenGoImports(table.Columns)
+	table.MetaGoImports = goImports(argTypes, imports)
+	resolveFinders(table)
+}
+
+// baseGoType 非空字段的 go 类型
+func baseGoType(col *Column) string {
+	if col.Unsigned {
+		if goType, ok := unsignedTypes[col.SQLType]; ok {
+			return goType
+		}
+	}
+	return types.SQLType2GoTypeString(col.SQLType)
+}
+
+func nullableGoType(goType string, nullType string) string {
+	switch nullType {
+	case NullTypeSQL:
+		if goType == "[]uint8" {
+			// []byte 可以直接接收 NULL
+			return goType
+		}
+		if nullType, ok := sqlNullTypes[goType]; ok {
+			return nullType
+		}
+		return "sql.Null[" + goType + "]"
+	case NullTypePointer:
+		if goType == "[]uint8" {
+			return goType
+		}
+		return "*" + goType
+	default:
+		return goType
+	}
+}
+
+// stdPackages 默认类型用到的标准库
+var stdPackages = map[string]string{
+	"time": "time",
+	"sql":  "database/sql",
+	"json": "encoding/json",
+}
+
+var qualifierRegexp = regexp.MustCompile(`(?:^|[^\w.])(\w+)\.`)
+
+// goImports 根据 go 类型生成 import 列表
+func goImports(goTypes []string, extra []string) []string {
+	set := make(map[string]struct{})
+	for _, goType := range goTypes {
+		for _, match := range qualifierRegexp.FindAllStringSubmatch(goType, -1) {
+			if imp, ok := stdPackages[match[1]]; ok {
+				set[imp] = struct{}{}
+			}
+		}
+	}
+	for _, imp := range extra {
+		set[imp] = struct{}{}
+	}
+	results := make([]string, 0, len(set))
+	for imp := range set {
+		results = append(results, imp)
+	}
+	sort.Strings(results)
+	return results
+}
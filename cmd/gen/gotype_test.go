@@ -0,0 +1,72 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTypeTestTable(t *testing.T) *Table {
+	tables, err := parseDDL("CREATE TABLE `order_info` (" +
+		"`id` bigint unsigned NOT NULL AUTO_INCREMENT," +
+		"`remark` varchar(64) DEFAULT NULL," +
+		"`uid` bigint unsigned DEFAULT NULL," +
+		"`status` enum('new','paid') NOT NULL," +
+		"`amount` decimal(10,2) NOT NULL," +
+		"`ext` json DEFAULT NULL," +
+		"`paid_at` datetime DEFAULT NULL," +
+		"PRIMARY KEY (`id`))")
+	assert.NoError(t, err)
+	return tables[0]
+}
+
+func TestResolveGoTypes(t *testing.T) {
+	goTypes := func(table *Table) []string {
+		var list []string
+		for _, col := range table.Columns {
+			list = append(list, col.GoType)
+		}
+		return list
+	}
+
+	table := newTypeTestTable(t)
+	assert.Equal(t, []string{"uint64", "string", "uint64", "string", "string", "string", "time.Time"}, goTypes(table))
+	assert.Equal(t, []string{"new", "paid"}, table.Columns[3].EnumValues)
+	assert.Equal(t, []string{"time"}, table.GoImports)
+
+	custom := &Custom{
+		NullType: NullTypeSQL,
+		Types: map[string]TypeOverride{
+			"JSON": {Type: "json.RawMessage"},
+		},
+	}
+	tableConfig := TableConfig{
+		Columns: map[string]TypeOverride{
+			"amount": {Type: "decimal.Decimal", Import: "github.com/shopspring/decimal"},
+		},
+	}
+	resolveGoTypes(table, custom, tableConfig)
+	assert.Equal(t, []string{"uint64", "sql.NullString", "sql.Null[uint64]", "string", "decimal.Decimal", "json.RawMessage", "sql.NullTime"}, goTypes(table))
+	assert.Equal(t, []string{"database/sql", "encoding/json", "github.com/shopspring/decimal"}, table.GoImports)
+	assert.Equal(t, []string{"encoding/json", "github.com/shopspring/decimal", "time"}, table.MetaGoImports)
+
+	custom.NullType = NullTypePointer
+	resolveGoTypes(table, custom, tableConfig)
+	assert.Equal(t, []string{"uint64", "*string", "*uint64", "string", "decimal.Decimal", "json.RawMessage", "*time.Time"}, goTypes(table))
+	assert.Equal(t, "uint64", table.Columns[2].ArgType)
+
+	// 生成的代码语法正确
+	for _, name := range []string{
+		"template/default/entity/{{.Table.Name}}.go.override.tmpl",
+		"template/default/meta/{{.Table.Name}}.go.override.tmpl",
+	} {
+		bs, err := ReadFile(name, true)
+		assert.NoError(t, err)
+		src, err := parse(string(bs), map[string]any{"TagName": "json", "Table": table})
+		assert.NoError(t, err)
+		_, err = parser.ParseFile(token.NewFileSet(), "", src, parser.AllErrors)
+		assert.NoError(t, err)
+	}
+}
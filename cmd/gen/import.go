@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/fengjx/daox"
+)
+
+// importCommand 导入数据命令
+// eg: gen import --driver mysql --dsn "root:1234@tcp(localhost:3306)/demo" --table user --conflict upsert user.csv
+func importCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "import csv, tsv or jsonl file into a table",
+		ArgsUsage: "FILE, - for stdin",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "driver",
+				Usage: "database driver, mysql or sqlite3",
+				Value: "mysql",
+			},
+			&cli.StringFlag{
+				Name:     "dsn",
+				Usage:    "database dsn",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "table",
+				Usage:    "target table",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "csv, tsv or jsonl, default by file extension",
+			},
+			&cli.StringFlag{
+				Name:  "conflict",
+				Usage: "conflict policy: error, ignore, replace or upsert",
+				Value: string(daox.ConflictError),
+			},
+			&cli.StringSliceFlag{
+				Name:  "conflict-columns",
+				Usage: "conflict columns for upsert, default primary key",
+			},
+			&cli.IntFlag{
+				Name:  "batch-size",
+				Usage: "rows per insert statement",
+				Value: 500,
+			},
+			&cli.BoolFlag{
+				Name:  "tx",
+				Usage: "import in one transaction, rollback when any line fails",
+			},
+		},
+		Action: runImport,
+	}
+}
+
+// importArgs 导入参数
+type importArgs struct {
+	Driver          string
+	Dsn             string
+	Table           string
+	File            string
+	Format          string
+	Conflict        string
+	ConflictColumns []string
+	BatchSize       int
+	Tx              bool
+}
+
+func runImport(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return fmt.Errorf("import file requires")
+	}
+	args := importArgs{
+		Driver:          ctx.String("driver"),
+		Dsn:             ctx.String("dsn"),
+		Table:           ctx.String("table"),
+		File:            ctx.Args().First(),
+		Format:          ctx.String("format"),
+		Conflict:        ctx.String("conflict"),
+		ConflictColumns: ctx.StringSlice("conflict-columns"),
+		BatchSize:       ctx.Int("batch-size"),
+		Tx:              ctx.Bool("tx"),
+	}
+	result, err := importFile(ctx.Context, args, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// importFile 根据数据库表结构注册表元信息后导入文件，失败的行输出到 w
+func importFile(ctx context.Context, args importArgs, w io.Writer) (*daox.ImportResult, error) {
+	dialect, err := daox.DialectOf(args.Driver)
+	if err != nil {
+		return nil, err
+	}
+	driverName := args.Driver
+	if dialect == daox.DialectSQLite {
+		driverName = "sqlite3"
+	}
+	format := daox.ExportFormat(args.Format)
+	if format == "" {
+		format = daox.ExportFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(args.File)), "."))
+	}
+	introspector, err := newIntrospector(&DS{Type: args.Driver, Dsn: args.Dsn})
+	if err != nil {
+		return nil, err
+	}
+	table, err := introspector.LoadTable(args.Table)
+	_ = introspector.Close()
+	if err != nil {
+		return nil, err
+	}
+	daox.UseTableMeta(tableMeta(table))
+
+	var r io.Reader = os.Stdin
+	if args.File != "-" {
+		f, err := os.Open(args.File)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	db, err := openDB(driverName, args.Dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	batchOpts := []daox.BatchInsertOption{daox.WithConflictPolicy(daox.ConflictPolicy(args.Conflict))}
+	if daox.ConflictPolicy(args.Conflict) == daox.ConflictUpsert {
+		batchOpts = append(batchOpts, daox.WithUpsert(args.ConflictColumns))
+	}
+	opts := []daox.ImportOption{
+		daox.WithImportFormat(format),
+		daox.WithImportBatchSize(args.BatchSize),
+		daox.WithImportBatchOptions(batchOpts...),
+	}
+	if args.Tx {
+		opts = append(opts, daox.WithImportTx())
+	}
+	result, err := daox.Import(ctx, db, args.Table, r, opts...)
+	if result != nil {
+		for _, lineErr := range result.Errors {
+			fmt.Fprintln(w, lineErr)
+		}
+		fmt.Fprintf(w, "read %d lines, affected %d rows, %d errors\n", result.Lines, result.Affected, len(result.Errors))
+	}
+	return result, err
+}
+
+// tableMeta 数据库表结构转换成表元信息
+func tableMeta(table *Table) daox.TableMeta {
+	meta := daox.TableMeta{
+		TableName:       table.Name,
+		PrimaryKey:      table.PrimaryKey.Name,
+		IsAutoIncrement: table.AutoIncrement,
+	}
+	for _, col := range table.Columns {
+		meta.Columns = append(meta.Columns, col.Name)
+		meta.ColumnMetas = append(meta.ColumnMetas, daox.ColumnMeta{
+			Name:     col.Name,
+			Type:     col.SQLType,
+			Nullable: col.Nullable,
+		})
+	}
+	return meta
+}
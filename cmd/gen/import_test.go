@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportFile(t *testing.T) {
+	dir := t.TempDir()
+	dsn := filepath.Join(dir, "import.db")
+	db := sqlx.MustOpen("sqlite3", dsn)
+	defer db.Close()
+	_, err := db.Exec(`CREATE TABLE import_user (
+		id integer primary key,
+		name varchar(32) not null,
+		score integer
+	)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO import_user (id, name, score) VALUES (1, 'old', 1)`)
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "user.csv")
+	assert.NoError(t, os.WriteFile(file, []byte("id,name,score\n1,fengjx,10\n2,daox,\n3,bad,abc\n"), 0o644))
+
+	var out bytes.Buffer
+	args := importArgs{
+		Driver:    "sqlite",
+		Dsn:       dsn,
+		Table:     "import_user",
+		File:      file,
+		Conflict:  "upsert",
+		BatchSize: 500,
+	}
+	result, err := importFile(context.Background(), args, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.Lines)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 4, result.Errors[0].Line)
+	assert.Contains(t, out.String(), "line 4:")
+	assert.Contains(t, out.String(), "read 3 lines")
+
+	var rows []struct {
+		ID    int64  `db:"id"`
+		Name  string `db:"name"`
+		Score *int64 `db:"score"`
+	}
+	assert.NoError(t, db.Select(&rows, "SELECT id, name, score FROM import_user ORDER BY id"))
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "fengjx", rows[0].Name)
+	assert.Equal(t, int64(10), *rows[0].Score)
+	assert.Equal(t, "daox", rows[1].Name)
+	assert.Nil(t, rows[1].Score)
+
+	args.Tx = true
+	args.Conflict = "error"
+	args.Format = "csv"
+	_, err = importFile(context.Background(), args, &out)
+	assert.Error(t, err)
+
+	args.Driver = "postgres"
+	_, err = importFile(context.Background(), args, &out)
+	assert.Error(t, err)
+}
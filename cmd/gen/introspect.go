@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+
+	"github.com/fengjx/daox/utils"
+)
+
+// Introspector 读取数据库表结构
+type Introspector interface {
+	// TableNames 返回所有表名
+	TableNames() ([]string, error)
+	// LoadTable 加载表元信息
+	LoadTable(tableName string) (*Table, error)
+	// Close 关闭数据库连接
+	Close() error
+}
+
+// newIntrospector 根据 ds.type 创建 Introspector
+func newIntrospector(ds *DS) (Introspector, error) {
+	switch strings.ToLower(ds.Type) {
+	case "mysql":
+		return newMySQLIntrospector(ds.Dsn)
+	case "sqlite", "sqlite3":
+		return newSQLiteIntrospector(ds.Dsn)
+	case "postgres", "postgresql":
+		return newPostgresIntrospector(ds.Dsn)
+	case "ddl":
+		return newDDLIntrospector(ds.Dsn)
+	default:
+		return nil, fmt.Errorf("unsupported ds type: %s", ds.Type)
+	}
+}
+
+// openDB 打开数据库连接并检查连接是否可用
+func openDB(driverName, dsn string) (*sqlx.DB, error) {
+	db, err := sqlx.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.Mapper = reflectx.NewMapperFunc("db", strings.ToTitle)
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// loadTables 加载需要生成的表的元信息，按表名排序
+// patterns 为命令行指定的表名或通配符，为空时根据配置选择
+func loadTables(config *Config, patterns ...string) ([]*Table, error) {
+	if config.DS == nil {
+		return nil, fmt.Errorf("ds config requires")
+	}
+	introspector, err := newIntrospector(config.DS)
+	if err != nil {
+		return nil, err
+	}
+	defer introspector.Close()
+	tableNames, err := selectTables(introspector, config.Target, patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(tableNames) == 0 {
+		return nil, fmt.Errorf("no table selected")
+	}
+	tables := make([]*Table, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		table, err := introspector.LoadTable(tableName)
+		if err != nil {
+			return nil, err
+		}
+		tableConfig := config.Target.Tables[tableName]
+		applyNaming(table, config.Target.Custom, tableConfig)
+		resolveGoTypes(table, config.Target.Custom, tableConfig)
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// newTable 创建表元信息，设置主键、go 类型等字段
+func newTable(name string, comment string, columns []Column, indexes []Index) *Table {
+	table := &Table{
+		Name:       name,
+		StructName: utils.GonicCase(name),
+		Comment:    comment,
+		Columns:    columns,
+		Indexes:    indexes,
+	}
+	for i := range table.Columns {
+		table.Columns[i].TableName = name
+	}
+	applyNaming(table, nil, TableConfig{})
+	resolveGoTypes(table, nil, TableConfig{})
+	return table
+}
+
+// baseSQLType 去掉类型参数，转成大写，eg: varchar(32) -> VARCHAR
+func baseSQLType(columnType string) string {
+	columnType = strings.TrimSpace(columnType)
+	if i := strings.IndexAny(columnType, "( "); i >= 0 {
+		columnType = columnType[:i]
+	}
+	return strings.ToUpper(columnType)
+}
+
+// groupIndexes 将按索引名称、字段顺序排列的索引字段合并成索引
+func groupIndexes(rows []indexColumn) []Index {
+	var indexes []Index
+	for _, row := range rows {
+		n := len(indexes)
+		if n > 0 && indexes[n-1].Name == row.Name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, row.Column)
+			continue
+		}
+		indexes = append(indexes, Index{
+			Name:    row.Name,
+			Unique:  row.Unique,
+			Primary: row.Primary,
+			Columns: []string{row.Column},
+		})
+	}
+	return indexes
+}
+
+// indexColumn 索引字段
+type indexColumn struct {
+	Name    string `db:"index_name"`
+	Unique  bool   `db:"is_unique"`
+	Primary bool   `db:"is_primary"`
+	Column  string `db:"column_name"`
+}
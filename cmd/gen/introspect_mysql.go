@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// mysqlIntrospector 通过 INFORMATION_SCHEMA 读取 mysql 表结构
+type mysqlIntrospector struct {
+	db     *sqlx.DB
+	dbName string
+}
+
+func newMySQLIntrospector(dsn string) (*mysqlIntrospector, error) {
+	dsnCfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openDB("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlIntrospector{db: db, dbName: dsnCfg.DBName}, nil
+}
+
+func (m *mysqlIntrospector) Close() error {
+	return m.db.Close()
+}
+
+func (m *mysqlIntrospector) TableNames() ([]string, error) {
+	querySQL := "SELECT `TABLE_NAME` FROM `INFORMATION_SCHEMA`.`TABLES`" +
+		" WHERE `TABLE_SCHEMA` = ? AND `TABLE_TYPE` = 'BASE TABLE' ORDER BY `TABLE_NAME`"
+	var names []string
+	if err := m.db.Select(&names, querySQL, m.dbName); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (m *mysqlIntrospector) LoadTable(tableName string) (*Table, error) {
+	querySQL := "SELECT `TABLE_NAME`, `ENGINE`, `AUTO_INCREMENT`, `TABLE_COMMENT` from" +
+		" `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA`=? AND TABLE_NAME = ?" +
+		" AND (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB')"
+	rows, err := m.db.Query(querySQL, m.dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var (
+		found    bool
+		name     string
+		engine   string
+		comment  *string
+		autoIncr *int64
+	)
+	for rows.Next() {
+		if err = rows.Scan(&name, &engine, &autoIncr, &comment); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("table %s not found in %s", tableName, m.dbName)
+	}
+	columns, err := m.loadColumns(tableName)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := m.loadIndexes(tableName)
+	if err != nil {
+		return nil, err
+	}
+	tableComment := ""
+	if comment != nil {
+		tableComment = *comment
+	}
+	table := newTable(name, tableComment, columns, indexes)
+	table.StoreEngine = engine
+	table.AutoIncrement = autoIncr != nil
+	return table, nil
+}
+
+func (m *mysqlIntrospector) loadColumns(tableName string) ([]Column, error) {
+	querySQL := `SELECT
+			column_name,
+			column_type,
+			column_comment, 
+			column_key,
+			ifnull(column_default, ''),
+			ifnull(extra, ''),
+			is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ? ORDER BY ORDINAL_POSITION`
+	rows, err := m.db.Query(querySQL, m.dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []Column
+	for rows.Next() {
+		var columnName string
+		var columnType string
+		var columnComment string
+		var columnKey string
+		var columnDefault string
+		var extra string
+		var isNullable string
+		err = rows.Scan(
+			&columnName,
+			&columnType,
+			&columnComment,
+			&columnKey,
+			&columnDefault,
+			&extra,
+			&isNullable,
+		)
+		if err != nil {
+			return nil, err
+		}
+		col := Column{}
+		col.Name = strings.Trim(columnName, "` ")
+		col.Comment = columnComment
+		col.DefaultValue = columnDefault
+		col.Extra = extra
+		// Remove the /* mariadb-5.3 */ suffix from coltypes
+		columnType = strings.TrimSuffix(columnType, "/* mariadb-5.3 */")
+		col.SQLType = baseSQLType(columnType)
+		parseColumnType(&col, columnType)
+		col.IsPrimaryKey = columnKey == "PRI"
+		col.Nullable = isNullable == "YES"
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+func (m *mysqlIntrospector) loadIndexes(tableName string) ([]Index, error) {
+	querySQL := `SELECT
+			index_name AS index_name,
+			non_unique = 0 AS is_unique,
+			index_name = 'PRIMARY' AS is_primary,
+			column_name AS column_name
+		FROM information_schema.statistics
+		WHERE table_schema = ? AND table_name = ? ORDER BY index_name, seq_in_index`
+	var rows []indexColumn
+	if err := m.db.Select(&rows, querySQL, m.dbName, tableName); err != nil {
+		return nil, err
+	}
+	return groupIndexes(rows), nil
+}
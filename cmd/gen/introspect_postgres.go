@@ -0,0 +1,227 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+
+	"github.com/fengjx/daox/utils"
+)
+
+// postgresIntrospector 通过 information_schema 和 pg_catalog 读取 postgres 表结构
+// 使用 current_schema() 作为 schema，可以在 dsn 中通过 search_path 指定
+type postgresIntrospector struct {
+	db *sqlx.DB
+}
+
+func newPostgresIntrospector(dsn string) (*postgresIntrospector, error) {
+	db, err := openDB("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresIntrospector{db: db}, nil
+}
+
+func (p *postgresIntrospector) Close() error {
+	return p.db.Close()
+}
+
+func (p *postgresIntrospector) TableNames() ([]string, error) {
+	querySQL := `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name`
+	var names []string
+	if err := p.db.Select(&names, querySQL); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (p *postgresIntrospector) LoadTable(tableName string) (*Table, error) {
+	querySQL := `SELECT COALESCE(obj_description(c.oid, 'pg_class'), '')
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p') AND n.nspname = current_schema() AND c.relname = $1`
+	var comment string
+	err := p.db.QueryRow(querySQL, tableName).Scan(&comment)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("table %s not found", tableName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, err := p.loadPrimaryKeys(tableName)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := p.loadColumns(tableName, primaryKeys)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := p.loadIndexes(tableName)
+	if err != nil {
+		return nil, err
+	}
+	table := newTable(tableName, comment, columns, indexes)
+	table.AutoIncrement = table.PrimaryKey.Extra == "auto_increment"
+	return table, nil
+}
+
+func (p *postgresIntrospector) loadPrimaryKeys(tableName string) ([]string, error) {
+	querySQL := `SELECT a.attname
+		FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indisprimary AND i.indrelid = (
+			SELECT c.oid FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = current_schema() AND c.relname = $1
+		)`
+	var primaryKeys []string
+	err := p.db.Select(&primaryKeys, querySQL, tableName)
+	return primaryKeys, err
+}
+
+func (p *postgresIntrospector) loadColumns(tableName string, primaryKeys []string) ([]Column, error) {
+	querySQL := `SELECT
+			c.column_name,
+			c.udt_name,
+			COALESCE(c.column_default, ''),
+			c.is_identity,
+			COALESCE(pgd.description, ''),
+			c.is_nullable,
+			COALESCE(c.character_maximum_length, 0),
+			COALESCE(c.numeric_precision, 0),
+			COALESCE(c.numeric_scale, 0)
+		FROM information_schema.columns c
+		LEFT JOIN pg_catalog.pg_statio_all_tables st
+			ON st.schemaname = c.table_schema AND st.relname = c.table_name
+		LEFT JOIN pg_catalog.pg_description pgd
+			ON pgd.objoid = st.relid AND pgd.objsubid = c.ordinal_position
+		WHERE c.table_schema = current_schema() AND c.table_name = $1
+		ORDER BY c.ordinal_position`
+	rows, err := p.db.Query(querySQL, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var (
+		columns  []Column
+		udtNames []string
+	)
+	for rows.Next() {
+		var (
+			name       string
+			udtName    string
+			dflt       string
+			isIdentity string
+			comment    string
+			isNullable string
+		)
+		col := Column{}
+		err = rows.Scan(&name, &udtName, &dflt, &isIdentity, &comment, &isNullable,
+			&col.Length, &col.Precision, &col.Scale)
+		if err != nil {
+			return nil, err
+		}
+		col.Name = name
+		col.SQLType = postgresSQLType(udtName)
+		col.ColumnType = udtName
+		col.Comment = comment
+		col.DefaultValue = dflt
+		col.Nullable = isNullable == "YES"
+		if _, ok := postgresTypes[udtName]; !ok && !strings.HasPrefix(udtName, "_") {
+			udtNames = append(udtNames, udtName)
+		}
+		for _, pk := range primaryKeys {
+			if pk == name {
+				col.IsPrimaryKey = true
+			}
+		}
+		// serial 类型的默认值是 nextval('xxx_seq'::regclass)
+		if isIdentity == "YES" || strings.HasPrefix(dflt, "nextval(") {
+			col.Extra = "auto_increment"
+			col.DefaultValue = ""
+		}
+		columns = append(columns, col)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	// 自定义类型如果是枚举，读取枚举值
+	for i := range columns {
+		if !utils.ContainsString(udtNames, columns[i].ColumnType) {
+			continue
+		}
+		var values []string
+		enumSQL := `SELECT e.enumlabel FROM pg_catalog.pg_enum e
+			JOIN pg_catalog.pg_type t ON t.oid = e.enumtypid
+			WHERE t.typname = $1 ORDER BY e.enumsortorder`
+		if err = p.db.Select(&values, enumSQL, columns[i].ColumnType); err != nil {
+			return nil, err
+		}
+		if len(values) > 0 {
+			columns[i].SQLType = "ENUM"
+			columns[i].EnumValues = values
+		}
+	}
+	return columns, nil
+}
+
+// postgresTypes postgres udt_name 转换成通用的 sql 类型
+var postgresTypes = map[string]string{
+	"int2":        "SMALLINT",
+	"int4":        "INT",
+	"int8":        "BIGINT",
+	"float4":      "REAL",
+	"float8":      "DOUBLE",
+	"numeric":     "NUMERIC",
+	"money":       "MONEY",
+	"bool":        "BOOL",
+	"varchar":     "VARCHAR",
+	"bpchar":      "CHAR",
+	"text":        "TEXT",
+	"uuid":        "UUID",
+	"bytea":       "BYTEA",
+	"date":        "DATE",
+	"time":        "TIME",
+	"timetz":      "TIME",
+	"timestamp":   "TIMESTAMP",
+	"timestamptz": "TIMESTAMPZ",
+	"json":        "JSON",
+	"jsonb":       "JSONB",
+	"xml":         "XML",
+}
+
+// postgresSQLType 数组类型的 udt_name 以 `_` 开头，其他未知类型（如枚举）按 TEXT 处理
+func postgresSQLType(udtName string) string {
+	if sqlType, ok := postgresTypes[udtName]; ok {
+		return sqlType
+	}
+	if strings.HasPrefix(udtName, "_") {
+		return "ARRAY"
+	}
+	return "TEXT"
+}
+
+func (p *postgresIntrospector) loadIndexes(tableName string) ([]Index, error) {
+	querySQL := `SELECT
+			i.relname AS index_name,
+			ix.indisunique AS is_unique,
+			ix.indisprimary AS is_primary,
+			a.attname AS column_name
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = current_schema() AND t.relname = $1
+		ORDER BY i.relname, k.ord`
+	var rows []indexColumn
+	if err := p.db.Select(&rows, querySQL, tableName); err != nil {
+		return nil, err
+	}
+	return groupIndexes(rows), nil
+}
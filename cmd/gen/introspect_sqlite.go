@@ -0,0 +1,148 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteIntrospector 通过 sqlite_master 和 pragma table_info 读取 sqlite 表结构
+type sqliteIntrospector struct {
+	db *sqlx.DB
+}
+
+func newSQLiteIntrospector(dsn string) (*sqliteIntrospector, error) {
+	db, err := openDB("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteIntrospector{db: db}, nil
+}
+
+func (s *sqliteIntrospector) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqliteIntrospector) TableNames() ([]string, error) {
+	querySQL := "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	var names []string
+	if err := s.db.Select(&names, querySQL); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (s *sqliteIntrospector) LoadTable(tableName string) (*Table, error) {
+	var createSQL string
+	err := s.db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Scan(&createSQL)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("table %s not found", tableName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	comments := sqliteColumnComments(createSQL)
+	rows, err := s.db.Query("SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid", tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var (
+		columns []Column
+		pkCount int
+		pkType  string
+	)
+	for rows.Next() {
+		var (
+			name      string
+			typ       string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err = rows.Scan(&name, &typ, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		col := Column{
+			Name:         name,
+			Comment:      comments[name],
+			SQLType:      sqliteSQLType(typ),
+			DefaultValue: strings.Trim(dfltValue.String, "'"),
+			IsPrimaryKey: pk > 0,
+			Nullable:     !notNull && pk == 0,
+		}
+		parseColumnType(&col, typ)
+		if pk > 0 {
+			pkCount++
+			pkType = typ
+		}
+		columns = append(columns, col)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	indexes, err := s.loadIndexes(tableName)
+	if err != nil {
+		return nil, err
+	}
+	table := newTable(tableName, "", columns, indexes)
+	// 只有声明类型为 INTEGER 的单字段主键是 rowid 的别名，插入时自动生成，BIGINT PRIMARY KEY 不是
+	if pkCount == 1 && strings.EqualFold(strings.TrimSpace(pkType), "INTEGER") {
+		table.AutoIncrement = true
+		for i := range table.Columns {
+			if table.Columns[i].IsPrimaryKey {
+				table.Columns[i].Extra = "auto_increment"
+				table.PrimaryKey = table.Columns[i]
+			}
+		}
+	}
+	return table, nil
+}
+
+func (s *sqliteIntrospector) loadIndexes(tableName string) ([]Index, error) {
+	querySQL := `SELECT
+			il.name AS index_name,
+			il."unique" AS is_unique,
+			il.origin = 'pk' AS is_primary,
+			ii.name AS column_name
+		FROM pragma_index_list(?) il, pragma_index_info(il.name) ii
+		ORDER BY il.name, ii.seqno`
+	var rows []indexColumn
+	if err := s.db.Select(&rows, querySQL, tableName); err != nil {
+		return nil, err
+	}
+	return groupIndexes(rows), nil
+}
+
+// sqliteSQLType sqlite 的 INTEGER 是 64 位整数，转换成 BIGINT
+// 没有声明类型的字段按 TEXT 处理
+func sqliteSQLType(typ string) string {
+	sqlType := baseSQLType(typ)
+	switch sqlType {
+	case "":
+		return "TEXT"
+	case "INTEGER", "INT8":
+		return "BIGINT"
+	default:
+		return sqlType
+	}
+}
+
+// sqliteColumnComments sqlite 不支持字段注释，从建表语句中字段定义后面的 `--` 注释中读取
+// eg: name TEXT NOT NULL, -- 用户名
+func sqliteColumnComments(createSQL string) map[string]string {
+	comments := make(map[string]string)
+	for _, line := range strings.Split(createSQL, "\n") {
+		def, comment, ok := strings.Cut(line, "--")
+		fields := strings.Fields(def)
+		if !ok || len(fields) == 0 {
+			continue
+		}
+		name := strings.Trim(fields[0], "`\"[]")
+		comments[name] = strings.TrimSpace(comment)
+	}
+	return comments
+}
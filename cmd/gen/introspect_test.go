@@ -0,0 +1,75 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteIntrospector(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "gen.db")
+	db := sqlx.MustOpen("sqlite3", dsn)
+	defer db.Close()
+	_, err := db.Exec(`CREATE TABLE user_info (
+		id integer primary key autoincrement, -- 主键
+		nickname varchar(32) not null default '', -- 昵称
+		score real,
+		ctime datetime
+	)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`CREATE UNIQUE INDEX uni_nickname ON user_info (nickname)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`CREATE INDEX idx_score_ctime ON user_info (score, ctime)`)
+	assert.NoError(t, err)
+
+	introspector, err := newIntrospector(&DS{Type: "sqlite", Dsn: dsn})
+	assert.NoError(t, err)
+	defer introspector.Close()
+	table, err := introspector.LoadTable("user_info")
+	assert.NoError(t, err)
+	assert.Equal(t, "UserInfo", table.StructName)
+	assert.True(t, table.AutoIncrement)
+	assert.Equal(t, "id", table.PrimaryKey.Name)
+	assert.Equal(t, []string{"time"}, table.GoImports)
+	assert.Equal(t, []Column{
+		{TableName: "user_info", Name: "id", FieldName: "ID", SQLType: "BIGINT", ColumnType: "INTEGER", Comment: "主键",
+			IsPrimaryKey: true, Extra: "auto_increment", GoType: "int64", ArgType: "int64"},
+		{TableName: "user_info", Name: "nickname", FieldName: "Nickname", SQLType: "VARCHAR", ColumnType: "varchar(32)", Comment: "昵称",
+			Length: 32, GoType: "string", ArgType: "string"},
+		{TableName: "user_info", Name: "score", FieldName: "Score", SQLType: "REAL", ColumnType: "REAL", Nullable: true, GoType: "float32", ArgType: "float32"},
+		{TableName: "user_info", Name: "ctime", FieldName: "Ctime", SQLType: "DATETIME", ColumnType: "datetime", Nullable: true, GoType: "time.Time", ArgType: "time.Time"},
+	}, table.Columns)
+	assert.Equal(t, []string{"ListByScore", "ListByScoreAndCtime", "GetByNickname"}, finderNames(table.Finders))
+
+	// BIGINT PRIMARY KEY 不是 rowid 的别名，不会自动生成
+	_, err = db.Exec(`CREATE TABLE user_token (id bigint primary key, token text)`)
+	assert.NoError(t, err)
+	table, err = introspector.LoadTable("user_token")
+	assert.NoError(t, err)
+	assert.False(t, table.AutoIncrement)
+	assert.Equal(t, "id", table.PrimaryKey.Name)
+	assert.Empty(t, table.PrimaryKey.Extra)
+
+	_, err = introspector.LoadTable("not_exist")
+	assert.Error(t, err)
+
+	_, err = newIntrospector(&DS{Type: "oracle"})
+	assert.Error(t, err)
+}
+
+func TestPostgresSQLType(t *testing.T) {
+	assert.Equal(t, "BIGINT", postgresSQLType("int8"))
+	assert.Equal(t, "TIMESTAMPZ", postgresSQLType("timestamptz"))
+	assert.Equal(t, "ARRAY", postgresSQLType("_int4"))
+	assert.Equal(t, "TEXT", postgresSQLType("my_enum"))
+}
+
+func finderNames(finders []Finder) []string {
+	var names []string
+	for _, finder := range finders {
+		names = append(names, finder.Name)
+	}
+	return names
+}
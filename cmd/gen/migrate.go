@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/fengjx/daox/migrate"
+)
+
+// migrateCommand 数据库迁移命令
+// eg: gen migrate --driver mysql --dsn "root:1234@tcp(localhost:3306)/demo" --dir ./migrations up
+func migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "apply versioned sql migrations",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "driver",
+				Usage: "database driver, mysql or sqlite3",
+				Value: "mysql",
+			},
+			&cli.StringFlag{
+				Name:     "dsn",
+				Usage:    "database dsn",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "dir",
+				Usage: "migration files dir",
+				Value: "./migrations",
+			},
+			&cli.StringFlag{
+				Name:  "table",
+				Usage: "migration record table",
+				Value: migrate.DefaultTableName,
+			},
+			&cli.DurationFlag{
+				Name:  "lock-timeout",
+				Usage: "timeout to acquire migration lock",
+				Value: time.Minute,
+			},
+			&cli.DurationFlag{
+				Name:  "lock-ttl",
+				Usage: "take over the lock table row older than ttl, 0 to disable",
+				Value: migrate.DefaultLockTTL,
+			},
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: "show migration status",
+				Action: withMigrator(func(ctx *cli.Context, m *migrate.Migrator) error {
+					list, err := m.Status(ctx.Context)
+					if err != nil {
+						return err
+					}
+					for _, status := range list {
+						state := "pending"
+						if status.Applied {
+							state = "applied at " + status.AppliedAt.Format(time.DateTime)
+						}
+						if status.Modified {
+							state += " (modified)"
+						}
+						if status.Missing {
+							state += " (missing)"
+						}
+						fmt.Printf("%d_%s\t%s\n", status.Version, status.Name, state)
+					}
+					return nil
+				}),
+			},
+			{
+				Name:      "up",
+				Usage:     "apply N pending migrations, all if N is omitted",
+				ArgsUsage: "[N]",
+				Action: withMigrator(func(ctx *cli.Context, m *migrate.Migrator) error {
+					n, err := argN(ctx, 0)
+					if err != nil {
+						return err
+					}
+					applied, err := m.Up(ctx.Context, n)
+					printMigrations("up", applied)
+					return err
+				}),
+			},
+			{
+				Name:      "down",
+				Usage:     "revert N applied migrations, 1 if N is omitted",
+				ArgsUsage: "[N]",
+				Action: withMigrator(func(ctx *cli.Context, m *migrate.Migrator) error {
+					n, err := argN(ctx, 1)
+					if err != nil {
+						return err
+					}
+					reverted, err := m.Down(ctx.Context, n)
+					printMigrations("down", reverted)
+					return err
+				}),
+			},
+			{
+				Name:  "redo",
+				Usage: "revert and reapply the last migration",
+				Action: withMigrator(func(ctx *cli.Context, m *migrate.Migrator) error {
+					mig, err := m.Redo(ctx.Context)
+					if mig != nil {
+						printMigrations("redo", []*migrate.Migration{mig})
+					}
+					return err
+				}),
+			},
+		},
+	}
+}
+
+func withMigrator(fn func(ctx *cli.Context, m *migrate.Migrator) error) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		db, err := sqlx.Open(ctx.String("driver"), ctx.String("dsn"))
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		migrations, err := migrate.LoadDir(ctx.String("dir"))
+		if err != nil {
+			return err
+		}
+		m, err := migrate.New(db, migrations,
+			migrate.WithTableName(ctx.String("table")),
+			migrate.WithLockTimeout(ctx.Duration("lock-timeout")),
+			migrate.WithLockTTL(ctx.Duration("lock-ttl")),
+		)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, m)
+	}
+}
+
+func argN(ctx *cli.Context, defaultVal int) (int, error) {
+	if ctx.NArg() == 0 {
+		return defaultVal, nil
+	}
+	n, err := strconv.Atoi(ctx.Args().First())
+	if err != nil {
+		return 0, fmt.Errorf("invalid N: %s", ctx.Args().First())
+	}
+	return n, nil
+}
+
+func printMigrations(action string, migrations []*migrate.Migration) {
+	for _, mig := range migrations {
+		fmt.Printf("%s\t%s\n", action, mig)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/fengjx/daox/utils"
+)
+
+// selectTables 选择需要生成的表，返回按表名排序的结果
+// 指定 patterns（--table 参数）时只选择匹配的表，否则选择 target.tables 中配置的表以及匹配 target.include 的表
+// 表名精确配置的表总是会被选择，通配符匹配到的表会排除匹配 target.exclude 的表
+func selectTables(introspector Introspector, target *ReverseTarget, patterns []string) ([]string, error) {
+	var exact, globs []string
+	if len(patterns) > 0 {
+		for _, pattern := range patterns {
+			if isGlob(pattern) {
+				globs = append(globs, pattern)
+			} else {
+				exact = append(exact, pattern)
+			}
+		}
+	} else {
+		for tableName := range target.Tables {
+			exact = append(exact, tableName)
+		}
+		globs = target.Include
+	}
+	for _, patterns := range [][]string{globs, target.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid table pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	selected := make(map[string]bool)
+	for _, tableName := range exact {
+		selected[tableName] = true
+	}
+	if len(globs) > 0 {
+		allNames, err := introspector.TableNames()
+		if err != nil {
+			return nil, err
+		}
+		for _, tableName := range allNames {
+			if matchAny(globs, tableName) && !matchAny(target.Exclude, tableName) {
+				selected[tableName] = true
+			}
+		}
+	}
+	tableNames := make([]string, 0, len(selected))
+	for tableName := range selected {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+	return tableNames, nil
+}
+
+// isGlob 是否包含通配符
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchAny 表名是否匹配任意一个通配符表达式
+func matchAny(patterns []string, tableName string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, tableName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyNaming 根据配置设置结构体名称和字段名称
+// 结构体名称优先使用 target.tables.{table}.struct-name，否则去掉 target.custom.table-prefix 前缀后转换，eg: t_user -> User
+func applyNaming(table *Table, custom *Custom, tableConfig TableConfig) {
+	table.StructName = tableConfig.StructName
+	if table.StructName == "" {
+		name := table.Name
+		if custom != nil {
+			for _, prefix := range custom.TablePrefix {
+				if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+					name = strings.TrimPrefix(name, prefix)
+					break
+				}
+			}
+		}
+		table.StructName = utils.GonicCase(name)
+	}
+	for i := range table.Columns {
+		col := &table.Columns[i]
+		col.FieldName = utils.GonicCase(col.Name)
+		if fieldName, ok := tableConfig.Rename[col.Name]; ok && fieldName != "" {
+			col.FieldName = fieldName
+		}
+		if col.IsPrimaryKey {
+			table.PrimaryKey = *col
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectTables(t *testing.T) {
+	introspector := &ddlIntrospector{tables: map[string]*Table{
+		"t_user":     {},
+		"t_user_bak": {},
+		"t_blog":     {},
+		"config":     {},
+	}}
+	target := &ReverseTarget{
+		Tables:  map[string]TableConfig{"config": {}, "t_user_bak": {}},
+		Include: []string{"t_*"},
+		Exclude: []string{"*_bak"},
+	}
+	names, err := selectTables(introspector, target, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config", "t_blog", "t_user", "t_user_bak"}, names)
+
+	names, err = selectTables(introspector, target, []string{"t_u*"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t_user"}, names)
+
+	names, err = selectTables(introspector, target, []string{"t_user_bak", "config"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config", "t_user_bak"}, names)
+
+	_, err = selectTables(introspector, target, []string{"t_["})
+	assert.Error(t, err)
+}
+
+func TestApplyNaming(t *testing.T) {
+	table := newTable("t_user", "", []Column{
+		{Name: "id", IsPrimaryKey: true},
+		{Name: "uname"},
+	}, nil)
+	assert.Equal(t, "TUser", table.StructName)
+
+	applyNaming(table, &Custom{TablePrefix: []string{"tb_", "t_"}}, TableConfig{
+		Rename: map[string]string{"id": "UserID", "uname": "Username"},
+	})
+	assert.Equal(t, "User", table.StructName)
+	assert.Equal(t, "UserID", table.Columns[0].FieldName)
+	assert.Equal(t, "UserID", table.PrimaryKey.FieldName)
+	assert.Equal(t, "Username", table.Columns[1].FieldName)
+
+	applyNaming(table, &Custom{TablePrefix: []string{"t_"}}, TableConfig{StructName: "Member"})
+	assert.Equal(t, "Member", table.StructName)
+	assert.Equal(t, "ID", table.Columns[0].FieldName)
+}
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "schema.sql"), []byte(`
+CREATE TABLE t_user (id bigint NOT NULL AUTO_INCREMENT, uname varchar(32) NOT NULL, PRIMARY KEY (id));
+CREATE TABLE t_log (id bigint NOT NULL, PRIMARY KEY (id));
+`), 0600)
+	assert.NoError(t, err)
+	out := filepath.Join(dir, "out")
+	config := &Config{
+		DS: &DS{Type: "ddl", Dsn: dir},
+		Target: &ReverseTarget{
+			Custom:  &Custom{OutDir: out, TagName: "json", TablePrefix: []string{"t_"}},
+			Tables:  map[string]TableConfig{"t_user": {Rename: map[string]string{"uname": "Username"}}},
+			Include: []string{"t_*"},
+		},
+	}
+	tables, err := loadTables(config, "t_user")
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	var buf bytes.Buffer
+	assert.NoError(t, dryRun(&buf, config, tables[0]))
+	assert.Contains(t, buf.String(), "(new)")
+	assert.Contains(t, buf.String(), "type User struct")
+	assert.Contains(t, buf.String(), "Username string `json:\"uname\"`")
+	_, err = os.Stat(out)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, gen(io.Discard, io.Discard, config, tables[0]))
+	buf.Reset()
+	assert.NoError(t, dryRun(&buf, config, tables[0]))
+	assert.Contains(t, buf.String(), "(unchanged)")
+
+	config.Target.Tables["t_user"] = TableConfig{}
+	tables, err = loadTables(config, "t_user")
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.NoError(t, dryRun(&buf, config, tables[0]))
+	assert.Contains(t, buf.String(), "(changed)")
+	assert.Contains(t, buf.String(), "-\tUsername string `json:\"uname\"`")
+	assert.Contains(t, buf.String(), "+\tUname string `json:\"uname\"`")
+
+	tables, err = loadTables(config)
+	assert.NoError(t, err)
+	assert.Len(t, tables, 2)
+}
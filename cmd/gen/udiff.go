@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext unified diff 每个变更块前后保留的行数
+const diffContext = 3
+
+// diffLine 逐行 diff 的结果，kind 为 ' '、'-'、'+'
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff 生成 unified 格式的 diff，内容相同时返回空字符串
+func unifiedDiff(name string, oldContent, newContent []byte) string {
+	if bytes.Equal(oldContent, newContent) {
+		return ""
+	}
+	lines := diffLines(splitLines(oldContent), splitLines(newContent))
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", name, name)
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+		// 找到变更块的范围，间隔小于 2*diffContext 的变更合并成一个块
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].kind == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = next
+		}
+		hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+		var oldCount, newCount int
+		for _, line := range lines[start:end] {
+			if line.kind != '+' {
+				oldCount++
+			}
+			if line.kind != '-' {
+				newCount++
+			}
+		}
+		// 没有行时起始行号为变更位置的前一行
+		if oldCount == 0 {
+			hunkOld--
+		}
+		if newCount == 0 {
+			hunkNew--
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", hunkOld, oldCount, hunkNew, newCount)
+		for _, line := range lines[start:end] {
+			buf.WriteByte(line.kind)
+			buf.WriteString(line.text)
+			buf.WriteByte('\n')
+		}
+		for _, line := range lines[i:end] {
+			if line.kind != '+' {
+				oldLine++
+			}
+			if line.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+func splitLines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// diffLines 基于最长公共子序列的逐行 diff
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] 为 a[i:] 和 b[j:] 的最长公共子序列长度
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	lines := make([]diffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{kind: ' ', text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{kind: '-', text: a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{kind: '+', text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{kind: '-', text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{kind: '+', text: b[j]})
+	}
+	return lines
+}
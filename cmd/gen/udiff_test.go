@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Empty(t, unifiedDiff("a.go", []byte("a\nb\n"), []byte("a\nb\n")))
+	oldContent := []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n")
+	newContent := []byte("1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n")
+	assert.Equal(t, `--- a.go
++++ a.go
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+@@ -13,3 +13,4 @@
+ 13
+ 14
+ 15
++16
+`, unifiedDiff("a.go", oldContent, newContent))
+	assert.Equal(t, "--- a.go\n+++ a.go\n@@ -0,0 +1,1 @@\n+a\n", unifiedDiff("a.go", nil, []byte("a\n")))
+}
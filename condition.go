@@ -0,0 +1,192 @@
+package daox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fengjx/daox/sqlbuilder"
+)
+
+var (
+	// ErrConditionType 不支持的条件类型
+	ErrConditionType = errors.New("[daox] unsupported condition type")
+	// ErrConditionOp 不支持的条件连接符
+	ErrConditionOp = errors.New("[daox] unsupported condition op")
+	// ErrConditionVals 条件参数个数不正确
+	ErrConditionVals = errors.New("[daox] invalid condition vals")
+)
+
+// likeEscape LIKE 语句的转义字符，使用 ! 而不是 \ 可以兼容 mysql、sqlite、postgres 的字符串转义规则
+const likeEscape = '!'
+
+// ConditionOperator 条件操作符，可以通过 RegisterConditionType 注册自定义操作符
+type ConditionOperator struct {
+	MinVals int // 最少参数个数
+	MaxVals int // 最多参数个数，小于 0 表示不限制
+	// Build 生成条件表达式，col 是加上反引号的字段名，eg: `name`
+	// 参数个数已经校验过，返回的表达式中使用 ? 作为参数占位符
+	Build func(col string, vals []any) (express string, args []any, err error)
+}
+
+var conditionOperators = struct {
+	sync.RWMutex
+	m map[ConditionType]ConditionOperator
+}{
+	m: map[ConditionType]ConditionOperator{
+		ConditionTypeEq:           binaryOperator("="),
+		ConditionTypeNotEq:        binaryOperator("!="),
+		ConditionTypeGt:           binaryOperator(">"),
+		ConditionTypeLt:           binaryOperator("<"),
+		ConditionTypeGte:          binaryOperator(">="),
+		ConditionTypeLte:          binaryOperator("<="),
+		ConditionTypeLike:         binaryOperator("LIKE"),
+		ConditionTypeNotLike:      binaryOperator("NOT LIKE"),
+		ConditionTypeRegex:        binaryOperator("REGEXP"),
+		ConditionTypeIn:           inOperator("IN"),
+		ConditionTypeNotIn:        inOperator("NOT IN"),
+		ConditionTypeIsNull:       nullOperator("IS NULL"),
+		ConditionTypeIsNotNull:    nullOperator("IS NOT NULL"),
+		ConditionTypePrefix:       likeOperator("", "%"),
+		ConditionTypeSuffix:       likeOperator("%", ""),
+		ConditionTypeContains:     likeOperator("%", "%"),
+		ConditionTypeBetween:      betweenOperator(),
+		ConditionTypeJSONContains: jsonContainsOperator(),
+	},
+}
+
+// RegisterConditionType 注册条件操作符，已存在的操作符会被覆盖
+func RegisterConditionType(conditionType ConditionType, operator ConditionOperator) {
+	conditionOperators.Lock()
+	defer conditionOperators.Unlock()
+	conditionOperators.m[conditionType] = operator
+}
+
+func getConditionOperator(conditionType ConditionType) (ConditionOperator, bool) {
+	conditionOperators.RLock()
+	defer conditionOperators.RUnlock()
+	operator, ok := conditionOperators.m[conditionType]
+	return operator, ok
+}
+
+// binaryOperator 一个参数的比较操作符，eg: `age` > ?
+func binaryOperator(op string) ConditionOperator {
+	return ConditionOperator{
+		MinVals: 1,
+		MaxVals: 1,
+		Build: func(col string, vals []any) (string, []any, error) {
+			return col + " " + op + " ?", vals, nil
+		},
+	}
+}
+
+// inOperator in 操作符，eg: `id` IN (?, ?)
+func inOperator(op string) ConditionOperator {
+	return ConditionOperator{
+		MinVals: 1,
+		MaxVals: -1,
+		Build: func(col string, vals []any) (string, []any, error) {
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(vals)), ", ")
+			return col + " " + op + " (" + placeholders + ")", vals, nil
+		},
+	}
+}
+
+// nullOperator 没有参数的操作符，eg: `name` IS NULL
+func nullOperator(op string) ConditionOperator {
+	return ConditionOperator{
+		Build: func(col string, _ []any) (string, []any, error) {
+			return col + " " + op, nil, nil
+		},
+	}
+}
+
+// likeOperator 模糊匹配，参数中的通配符会被转义
+func likeOperator(prefix, suffix string) ConditionOperator {
+	return ConditionOperator{
+		MinVals: 1,
+		MaxVals: 1,
+		Build: func(col string, vals []any) (string, []any, error) {
+			val, ok := vals[0].(string)
+			if !ok {
+				return "", nil, fmt.Errorf("%w: like value must be string, got %T", ErrConditionVals, vals[0])
+			}
+			pattern := prefix + EscapeLike(val) + suffix
+			return col + " LIKE ? ESCAPE '" + string(likeEscape) + "'", []any{pattern}, nil
+		},
+	}
+}
+
+func betweenOperator() ConditionOperator {
+	return ConditionOperator{
+		MinVals: 2,
+		MaxVals: 2,
+		Build: func(col string, vals []any) (string, []any, error) {
+			return col + " BETWEEN ? AND ?", vals, nil
+		},
+	}
+}
+
+// jsonContainsOperator JSON_CONTAINS(`tags`, ?)，参数不是字符串时会序列化成 json
+func jsonContainsOperator() ConditionOperator {
+	return ConditionOperator{
+		MinVals: 1,
+		MaxVals: 1,
+		Build: func(col string, vals []any) (string, []any, error) {
+			val, ok := vals[0].(string)
+			if !ok {
+				bs, err := json.Marshal(vals[0])
+				if err != nil {
+					return "", nil, fmt.Errorf("%w: %s", ErrConditionVals, err.Error())
+				}
+				val = string(bs)
+			}
+			return "JSON_CONTAINS(" + col + ", ?)", []any{val}, nil
+		},
+	}
+}
+
+// EscapeLike 转义 LIKE 语句中的通配符，需要配合 ESCAPE '!' 使用
+func EscapeLike(val string) string {
+	var sb strings.Builder
+	for _, c := range val {
+		if c == '%' || c == '_' || c == likeEscape {
+			sb.WriteRune(likeEscape)
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// buildCondition 根据查询条件生成 where 语句，条件类型不支持或者参数个数不正确时返回错误
+func buildCondition(conditions []Condition) (sqlbuilder.ConditionBuilder, error) {
+	where := sqlbuilder.SC()
+	for _, c := range conditions {
+		if c.Disable {
+			continue
+		}
+		operator, ok := getConditionOperator(c.ConditionType)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrConditionType, c.ConditionType)
+		}
+		n := len(c.Vals)
+		if n < operator.MinVals || (operator.MaxVals >= 0 && n > operator.MaxVals) {
+			return nil, fmt.Errorf("%w: %s %s got %d vals", ErrConditionVals, c.Field, c.ConditionType, n)
+		}
+		express, args, err := operator.Build(sqlbuilder.Quote(c.Field), c.Vals)
+		if err != nil {
+			return nil, err
+		}
+		switch c.Op {
+		case OpAnd, "":
+			where.And(express, args...)
+		case OpOr:
+			where.Or(express, args...)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrConditionOp, c.Op)
+		}
+	}
+	return where, nil
+}
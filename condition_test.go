@@ -0,0 +1,159 @@
+package daox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+)
+
+func TestConditionTypes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		condition daox.Condition
+		wantSQL   string
+		wantArgs  []any
+		wantErr   error
+	}{
+		{
+			name:      "in",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeIn, Field: "id", Vals: []any{1, 2}},
+			wantSQL:   "SELECT * FROM `user` WHERE `id` IN (?, ?);",
+			wantArgs:  []any{1, 2},
+		},
+		{
+			name:      "between",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeBetween, Field: "age", Vals: []any{18, 30}},
+			wantSQL:   "SELECT * FROM `user` WHERE `age` BETWEEN ? AND ?;",
+			wantArgs:  []any{18, 30},
+		},
+		{
+			name:      "is null",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeIsNull, Field: "email"},
+			wantSQL:   "SELECT * FROM `user` WHERE `email` IS NULL;",
+		},
+		{
+			name:      "is not null",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeIsNotNull, Field: "email"},
+			wantSQL:   "SELECT * FROM `user` WHERE `email` IS NOT NULL;",
+		},
+		{
+			name:      "prefix",
+			condition: daox.Condition{ConditionType: daox.ConditionTypePrefix, Field: "name", Vals: []any{"100%_a!"}},
+			wantSQL:   "SELECT * FROM `user` WHERE `name` LIKE ? ESCAPE '!';",
+			wantArgs:  []any{"100!%!_a!!%"},
+		},
+		{
+			name:      "suffix",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeSuffix, Field: "name", Vals: []any{"jo"}},
+			wantSQL:   "SELECT * FROM `user` WHERE `name` LIKE ? ESCAPE '!';",
+			wantArgs:  []any{"%jo"},
+		},
+		{
+			name:      "contains",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeContains, Field: "name", Vals: []any{"jo"}},
+			wantSQL:   "SELECT * FROM `user` WHERE `name` LIKE ? ESCAPE '!';",
+			wantArgs:  []any{"%jo%"},
+		},
+		{
+			name:      "json contains",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeJSONContains, Field: "tags", Vals: []any{[]string{"a"}}},
+			wantSQL:   "SELECT * FROM `user` WHERE JSON_CONTAINS(`tags`, ?);",
+			wantArgs:  []any{`["a"]`},
+		},
+		{
+			name:      "regex",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeRegex, Field: "name", Vals: []any{"^a"}},
+			wantSQL:   "SELECT * FROM `user` WHERE `name` REGEXP ?;",
+			wantArgs:  []any{"^a"},
+		},
+		{
+			name:      "empty vals",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeEq, Field: "id"},
+			wantErr:   daox.ErrConditionVals,
+		},
+		{
+			name:      "empty in",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeIn, Field: "id", Vals: []any{}},
+			wantErr:   daox.ErrConditionVals,
+		},
+		{
+			name:      "between one val",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeBetween, Field: "age", Vals: []any{1}},
+			wantErr:   daox.ErrConditionVals,
+		},
+		{
+			name:      "like not string",
+			condition: daox.Condition{ConditionType: daox.ConditionTypeContains, Field: "name", Vals: []any{1}},
+			wantErr:   daox.ErrConditionVals,
+		},
+		{
+			name:      "unknown type",
+			condition: daox.Condition{ConditionType: "unknown", Field: "id", Vals: []any{1}},
+			wantErr:   daox.ErrConditionType,
+		},
+		{
+			name:      "unknown op",
+			condition: daox.Condition{Op: "xor", ConditionType: daox.ConditionTypeEq, Field: "id", Vals: []any{1}},
+			wantErr:   daox.ErrConditionOp,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := daox.QueryRecord{TableName: "user", Conditions: []daox.Condition{tc.condition}}
+			sql, args, err := q.ToSQLArgs()
+			assert.ErrorIs(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantSQL, sql)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestRegisterConditionType(t *testing.T) {
+	daox.RegisterConditionType("mod", daox.ConditionOperator{
+		MinVals: 2,
+		MaxVals: 2,
+		Build: func(col string, vals []any) (string, []any, error) {
+			return col + " % ? = ?", vals, nil
+		},
+	})
+	q := daox.QueryRecord{
+		TableName: "user",
+		Conditions: []daox.Condition{
+			{Op: daox.OpAnd, ConditionType: "mod", Field: "id", Vals: []any{2, 0}},
+			{Op: daox.OpOr, ConditionType: daox.ConditionTypeEq, Field: "id", Vals: []any{1}},
+		},
+	}
+	sql, args, err := q.ToSQLArgs()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM `user` WHERE `id` % ? = ? OR `id` = ?;", sql)
+	assert.Equal(t, []any{2, 0, 1}, args)
+}
+
+func TestFindEscapeLike(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_find_escape_like"
+	before(t, tableName)
+	q := daox.QueryRecord{
+		TableName: tableName,
+		Fields:    []string{"id", "name"},
+		Conditions: []daox.Condition{
+			{ConditionType: daox.ConditionTypePrefix, Field: "name", Vals: []any{"u-"}},
+		},
+		Page: &daox.Page{Limit: 100},
+	}
+	list, _, err := daox.Find[DemoInfo](ctx, newDb(), q)
+	assert.NoError(t, err)
+	assert.Len(t, list, 10)
+
+	// _ 是通配符，转义后只匹配字面量
+	q.Conditions[0].Vals = []any{"u_"}
+	list, _, err = daox.Find[DemoInfo](ctx, newDb(), q)
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+}
@@ -27,14 +27,16 @@ var (
 // Dao 数据访问对象，封装了数据库操作的基础方法
 type Dao struct {
 	lock        sync.Mutex
-	options     *Options          // 配置选项
-	masterDB    *DB               // 主库连接
-	readDB      *DB               // 从库连接
-	mapper      *reflectx.Mapper  // 字段映射器
-	TableMeta   *TableMeta        // 表元数据
-	ifNullVals  map[string]string // NULL值替换配置
-	omitColumns []string          // 忽略的字段列表
-	executor    engine.Executor   // SQL执行器，用于事务等场景
+	options     *Options             // 配置选项
+	masterDB    *DB                  // 主库连接
+	readDB      *DB                  // 从库连接
+	mapper      *reflectx.Mapper     // 字段映射器
+	TableMeta   *TableMeta           // 表元数据
+	ifNullVals  map[string]string    // NULL值替换配置
+	omitColumns []string             // 忽略的字段列表
+	executor    engine.Executor      // SQL执行器，用于事务等场景
+	relations   map[string]*relation // 关联关系
+	preloads    []string             // 查询时预加载的关联关系
 }
 
 // NewDao 创建一个新的 dao 对象
@@ -65,6 +67,7 @@ func NewDao[T Model](tableName string, primaryKey string, opts ...Option) *Dao {
 		IsAutoIncrement: options.autoIncrement,
 		Columns:         columns,
 	}
+	meta.ColumnMetas, meta.Indexes = parseColumnMetas(options.mapper, structType, tableName, columns)
 	// 合并 hooks
 	hooks := mergeHooks(options)
 	// 设置主库连接
@@ -89,6 +92,7 @@ func NewDao[T Model](tableName string, primaryKey string, opts ...Option) *Dao {
 		ifNullVals:  options.ifNullVals,
 		omitColumns: options.omitColumns,
 		options:     options,
+		relations:   make(map[string]*relation),
 	}
 	global.registerMeta(dao.TableMeta)
 	return dao
@@ -115,6 +119,7 @@ func NewDaoByMeta(m Meta, opts ...Option) *Dao {
 		PrimaryKey:      m.PrimaryKey(),
 		Columns:         m.Columns(),
 		IsAutoIncrement: m.IsAutoIncrement(),
+		ColumnTypes:     parseColumnTypes(m),
 	}
 	hooks := mergeHooks(options)
 	master := options.master
@@ -137,6 +142,7 @@ func NewDaoByMeta(m Meta, opts ...Option) *Dao {
 		ifNullVals:  options.ifNullVals,
 		omitColumns: options.omitColumns,
 		options:     options,
+		relations:   make(map[string]*relation),
 	}
 	global.registerMeta(dao.TableMeta)
 	return dao
@@ -160,19 +166,30 @@ func (d *Dao) Selector(columns ...string) *sqlbuilder.Selector {
 		selector.IfNullVals(d.ifNullVals)
 	}
 	selector.Queryer(d.getQueryer())
+	if d.tenantColumn() != "" {
+		selector.Scopes(d.tenantScope)
+	}
 	return selector
 }
 
 // Updater 创建当前表的更新构建器
 // 返回值: 更新构建器对象
 func (d *Dao) Updater() *sqlbuilder.Updater {
-	return d.SQLBuilder().Update().Execer(d.getExecer())
+	updater := d.SQLBuilder().Update().Execer(d.getExecer())
+	if d.tenantColumn() != "" {
+		updater.Scopes(d.tenantScope)
+	}
+	return updater
 }
 
 // Deleter 创建当前表的删除构建器
 // 返回值: 删除构建器对象
 func (d *Dao) Deleter() *sqlbuilder.Deleter {
-	return d.SQLBuilder().Delete().Execer(d.getExecer())
+	deleter := d.SQLBuilder().Delete().Execer(d.getExecer())
+	if d.tenantColumn() != "" {
+		deleter.Scopes(d.tenantScope)
+	}
+	return deleter
 }
 
 // Inserter 创建当前表的插入构建器
@@ -240,6 +257,9 @@ func (d *Dao) SaveContext(ctx context.Context, dest Model, opts ...InsertOption)
 	for _, o := range opts {
 		o(opt)
 	}
+	if err := d.stampTenant(ctx, dest); err != nil {
+		return 0, err
+	}
 	result, err := d.SQLBuilder().Insert().Execer(d.getExecer()).
 		Columns(d.getSaveColumns(opt)...).
 		NamedExecContext(ctx, dest)
@@ -258,6 +278,9 @@ func (d *Dao) ReplaceInto(dest Model, opts ...InsertOption) (sql.Result, error)
 // ReplaceIntoContext replace into table，携带上下文
 // omitColumns 不需要 insert 的字段
 func (d *Dao) ReplaceIntoContext(ctx context.Context, model Model, opts ...InsertOption) (sql.Result, error) {
+	if err := d.stampTenant(ctx, model); err != nil {
+		return nil, err
+	}
 	return d.Inserter(opts...).
 		IsReplaceInto(true).
 		NamedExecContext(ctx, model)
@@ -272,6 +295,9 @@ func (d *Dao) IgnoreInto(model Model, opts ...InsertOption) (sql.Result, error)
 // IgnoreIntoContext 使用 INSERT IGNORE INTO 如果记录已存在则忽略，携带上下文
 // omitColumns 不需要 insert 的字段
 func (d *Dao) IgnoreIntoContext(ctx context.Context, model Model, opts ...InsertOption) (sql.Result, error) {
+	if err := d.stampTenant(ctx, model); err != nil {
+		return nil, err
+	}
 	return d.Inserter(opts...).
 		IsIgnoreInto(true).
 		NamedExecContext(ctx, model)
@@ -287,6 +313,9 @@ func (d *Dao) BatchSave(models any, opts ...InsertOption) (sql.Result, error) {
 // omitColumns 不需要 insert 的字段
 // models 是一个批量 insert 的 slice
 func (d *Dao) BatchSaveContext(ctx context.Context, models any, opts ...InsertOption) (sql.Result, error) {
+	if err := d.stampTenant(ctx, models); err != nil {
+		return nil, err
+	}
 	return d.Inserter(opts...).
 		NamedExecContext(ctx, models)
 }
@@ -302,6 +331,9 @@ func (d *Dao) BatchReplaceInto(models any, opts ...InsertOption) (sql.Result, er
 // models 是一个 slice
 // omitColumns 不需要 insert 的字段
 func (d *Dao) BatchReplaceIntoContext(ctx context.Context, models any, opts ...InsertOption) (sql.Result, error) {
+	if err := d.stampTenant(ctx, models); err != nil {
+		return nil, err
+	}
 	return d.Inserter(opts...).
 		IsReplaceInto(true).
 		NamedExecContext(ctx, models)
@@ -334,9 +366,13 @@ func (d *Dao) GetByColumnContext(ctx context.Context, kv *KV, dest Model) (bool,
 	if kv == nil {
 		return false, nil
 	}
-	return d.Selector().Queryer(d.getQueryer()).
+	exist, err := d.Selector().Queryer(d.getQueryer()).
 		Where(ql.C(ql.Col(kv.Key).EQ(kv.Value))).
 		GetContext(ctx, dest)
+	if err != nil || !exist {
+		return exist, err
+	}
+	return true, d.loadRelations(ctx, dest)
 }
 
 // ListByColumns 指定字段多个值查询多条数据
@@ -351,10 +387,14 @@ func (d *Dao) ListByColumnsContext(ctx context.Context, kvs *MultiKV, dest any)
 	if kvs == nil || len(kvs.Values) == 0 {
 		return nil
 	}
-	return d.Selector().Queryer(d.getQueryer()).
+	err := d.Selector().Queryer(d.getQueryer()).
 		Columns(d.DBColumns()...).
 		Where(ql.C(ql.Col(kvs.Key).In(kvs.Values...))).
 		SelectContext(ctx, dest)
+	if err != nil {
+		return err
+	}
+	return d.loadRelations(ctx, dest)
 }
 
 // List 指定字段查询多条数据
@@ -364,10 +404,14 @@ func (d *Dao) List(kv *KV, dest any) error {
 
 // ListContext 指定字段查询多条数据，携带上下文
 func (d *Dao) ListContext(ctx context.Context, kv *KV, dest any) error {
-	return d.Selector().Queryer(d.getQueryer()).
+	err := d.Selector().Queryer(d.getQueryer()).
 		Columns(d.DBColumns()...).
 		Where(ql.C(ql.Col(kv.Key).EQ(kv.Value))).
 		SelectContext(ctx, dest)
+	if err != nil {
+		return err
+	}
+	return d.loadRelations(ctx, dest)
 }
 
 // GetByID 根据 id 查询单条数据
@@ -403,12 +447,18 @@ func (d *Dao) UpdateFieldContext(ctx context.Context, idValue any, fieldMap map[
 		return false, ErrUpdatePrimaryKeyRequire
 	}
 
-	updater := d.Updater().Execer(d.getExecer())
-	for col, val := range fieldMap {
-		updater.Set(col, val)
-	}
-	updater.Where(ql.C(ql.Col(d.TableMeta.PrimaryKey).EQ(idValue)))
-	rows, err := updater.ExecContext(ctx)
+	where := ql.C(ql.Col(d.TableMeta.PrimaryKey).EQ(idValue))
+	rows, err := d.auditWrite(ctx, AuditOpUpdate, where, nil, func(ctx context.Context, dao *Dao) (int64, error) {
+		updater := dao.Updater()
+		for col, val := range fieldMap {
+			// 租户字段不允许修改
+			if col == dao.tenantColumn() {
+				continue
+			}
+			updater.Set(col, val)
+		}
+		return updater.Where(where).ExecContext(ctx)
+	})
 	if err != nil {
 		return false, err
 	}
@@ -439,10 +489,20 @@ func (d *Dao) UpdateByCondContext(ctx context.Context, model Model, where sqlbui
 	if len(global.omitColumns) > 0 {
 		omitColumns = append(omitColumns, global.omitColumns...)
 	}
-	updater := d.Updater().Execer(d.getExecer()).
-		Columns(d.DBColumns(omitColumns...)...).
-		Where(where)
-	affected, err := updater.NamedExecContext(ctx, model)
+	if col := d.tenantColumn(); col != "" {
+		// 租户字段不允许修改
+		omitColumns = append(omitColumns, col)
+	}
+	var namedArgs any
+	if d.auditSink() != nil {
+		namedArgs = d.auditNamedArgs(model)
+	}
+	affected, err := d.auditWrite(ctx, AuditOpUpdate, where, namedArgs, func(ctx context.Context, dao *Dao) (int64, error) {
+		return dao.Updater().
+			Columns(dao.DBColumns(omitColumns...)...).
+			Where(where).
+			NamedExecContext(ctx, model)
+	})
 	if err != nil {
 		return false, err
 	}
@@ -450,7 +510,9 @@ func (d *Dao) UpdateByCondContext(ctx context.Context, model Model, where sqlbui
 }
 
 func (d *Dao) deleteByCondContext(ctx context.Context, where sqlbuilder.ConditionBuilder) (int64, error) {
-	return d.Deleter().Execer(d.getExecer()).Where(where).ExecContext(ctx)
+	return d.auditWrite(ctx, AuditOpDelete, where, nil, func(ctx context.Context, dao *Dao) (int64, error) {
+		return dao.Deleter().Where(where).ExecContext(ctx)
+	})
 }
 
 // DeleteByColumn 按字段名删除
@@ -504,6 +566,8 @@ func (d *Dao) With(master, read *sqlx.DB) *Dao {
 		mapper:     d.mapper,
 		ifNullVals: d.ifNullVals,
 		options:    d.options,
+		relations:  d.relations,
+		preloads:   d.preloads,
 	}
 	return newDao
 }
@@ -517,6 +581,8 @@ func (d *Dao) WithTableName(tableName string) *Dao {
 		mapper:     d.mapper,
 		ifNullVals: d.ifNullVals,
 		options:    d.options,
+		relations:  d.relations,
+		preloads:   d.preloads,
 	}
 	return newDao
 }
@@ -530,6 +596,8 @@ func (d *Dao) WithExecutor(executor engine.Executor) *Dao {
 		ifNullVals: d.ifNullVals,
 		options:    d.options,
 		executor:   executor,
+		relations:  d.relations,
+		preloads:   d.preloads,
 	}
 	return newDao
 }
@@ -50,6 +50,11 @@ func (d *DB) GetContext(ctx context.Context, dest any, query string, args ...any
 	return doGet(ctx, d.DB, dest, query, args, d.hook)
 }
 
+// StreamContext 流式查询，通过 fn 逐行处理结果
+func (d *DB) StreamContext(ctx context.Context, fn engine.RowsHandler, query string, args ...any) error {
+	return doStream(ctx, d.DB, fn, query, args, d.hook)
+}
+
 // Beginx 打开一个事务
 func (d *DB) Beginx() (*Tx, error) {
 	tx, err := d.DB.Beginx()
@@ -62,25 +67,43 @@ func (d *DB) Beginx() (*Tx, error) {
 	}, nil
 }
 
+// getExecutorContext 获取本次执行的执行上下文
+// 上下文中存在执行上下文时复制一份，保留构造器设置的表名等信息，不会修改原来的执行上下文，可以并发执行
+func getExecutorContext(ctx context.Context, query string) *engine.ExecutorContext {
+	ec := &engine.ExecutorContext{}
+	if parent := engine.GetExecutorContext(ctx); parent != nil {
+		ec = parent.Clone()
+	}
+	if ec.Type == "" {
+		ec.Type = engine.ParseSQLType(query)
+	}
+	if ec.TableName == "" {
+		ec.TableName = engine.ParseTableName(query)
+	}
+	ec.SQL = query
+	ec.Start = time.Now()
+	return ec
+}
+
 func doNamedExec(ctx context.Context, execer engine.Execer, execSQL string, arg any, hook engine.Hook) (sql.Result, error) {
 	if hook == nil {
 		return execer.NamedExecContext(ctx, execSQL, arg)
 	}
-	ec := engine.GetExecutorContext(ctx)
-	if ec == nil {
-		ec = &engine.ExecutorContext{
-			Type:      engine.ParseSQLType(execSQL),
-			TableName: engine.ParseTableName(execSQL),
-			SQL:       execSQL,
-			NameArgs:  arg,
-			Start:     time.Now(),
-		}
-	}
+	ec := getExecutorContext(ctx, execSQL)
+	ec.NameArgs = arg
 	err := hook.Before(ctx, ec)
 	if err != nil {
 		return nil, err
 	}
-	result, err := execer.NamedExecContext(ctx, execSQL, arg)
+	// hook 可以通过 SetContext 传递 span 等上下文
+	execCtx := ec.Context(ctx)
+	var result sql.Result
+	if ec.IsSkip() {
+		result = ec.SkipResult()
+	} else {
+		// 使用 hook 修改后的 sql 和参数
+		result, err = execer.NamedExecContext(execCtx, ec.SQL, ec.NameArgs)
+	}
 	er := &engine.ExecutorResult{
 		Err:      err,
 		Duration: time.Since(ec.Start),
@@ -89,7 +112,7 @@ func doNamedExec(ctx context.Context, execer engine.Execer, execSQL string, arg
 		affected, _ := result.RowsAffected()
 		er.Affected = affected
 	}
-	hook.After(ctx, ec, er)
+	hook.After(execCtx, ec, er)
 	if err != nil {
 		return nil, err
 	}
@@ -100,21 +123,21 @@ func doExec(ctx context.Context, execer engine.Execer, execSQL string, args []an
 	if hook == nil {
 		return execer.ExecContext(ctx, execSQL, args...)
 	}
-	ec := engine.GetExecutorContext(ctx)
-	if ec == nil {
-		ec = &engine.ExecutorContext{
-			Type:      engine.ParseSQLType(execSQL),
-			TableName: engine.ParseTableName(execSQL),
-			SQL:       execSQL,
-			Args:      args,
-			Start:     time.Now(),
-		}
-	}
+	ec := getExecutorContext(ctx, execSQL)
+	ec.Args = args
 	err := hook.Before(ctx, ec)
 	if err != nil {
 		return nil, err
 	}
-	result, err := execer.ExecContext(ctx, execSQL, args...)
+	// hook 可以通过 SetContext 传递 span 等上下文
+	execCtx := ec.Context(ctx)
+	var result sql.Result
+	if ec.IsSkip() {
+		result = ec.SkipResult()
+	} else {
+		// 使用 hook 修改后的 sql 和参数
+		result, err = execer.ExecContext(execCtx, ec.SQL, ec.Args...)
+	}
 	er := &engine.ExecutorResult{
 		Err:      err,
 		Duration: time.Since(ec.Start),
@@ -123,7 +146,7 @@ func doExec(ctx context.Context, execer engine.Execer, execSQL string, args []an
 		affected, _ := result.RowsAffected()
 		er.Affected = affected
 	}
-	hook.After(ctx, ec, er)
+	hook.After(execCtx, ec, er)
 	if err != nil {
 		return nil, err
 	}
@@ -134,21 +157,19 @@ func doSelect(ctx context.Context, queryer engine.Queryer, dest any, query strin
 	if hook == nil {
 		return queryer.SelectContext(ctx, dest, query, args...)
 	}
-	ec := engine.GetExecutorContext(ctx)
-	if ec == nil {
-		ec = &engine.ExecutorContext{
-			Type:      engine.ParseSQLType(query),
-			TableName: engine.ParseTableName(query),
-			SQL:       query,
-			Args:      args,
-			Start:     time.Now(),
-		}
-	}
+	ec := getExecutorContext(ctx, query)
+	ec.Args = args
+	ec.Dest = dest
 	err := hook.Before(ctx, ec)
 	if err != nil {
 		return err
 	}
-	err = queryer.SelectContext(ctx, dest, query, args...)
+	// hook 可以通过 SetContext 传递 span 等上下文
+	execCtx := ec.Context(ctx)
+	if !ec.IsSkip() {
+		// 使用 hook 修改后的 sql 和参数
+		err = queryer.SelectContext(execCtx, dest, ec.SQL, ec.Args...)
+	}
 	er := &engine.ExecutorResult{
 		Err:      err,
 		Duration: time.Since(ec.Start),
@@ -156,7 +177,7 @@ func doSelect(ctx context.Context, queryer engine.Queryer, dest any, query strin
 	if err == nil {
 		er.QueryRows = int64(utils.GetLength(dest))
 	}
-	hook.After(ctx, ec, er)
+	hook.After(execCtx, ec, er)
 	return err
 }
 
@@ -164,21 +185,19 @@ func doGet(ctx context.Context, queryer engine.Queryer, dest any, query string,
 	if hook == nil {
 		return queryer.GetContext(ctx, dest, query, args...)
 	}
-	ec := engine.GetExecutorContext(ctx)
-	if ec == nil {
-		ec = &engine.ExecutorContext{
-			Type:      engine.ParseSQLType(query),
-			TableName: engine.ParseTableName(query),
-			SQL:       query,
-			Args:      args,
-			Start:     time.Now(),
-		}
-	}
+	ec := getExecutorContext(ctx, query)
+	ec.Args = args
+	ec.Dest = dest
 	err := hook.Before(ctx, ec)
 	if err != nil {
 		return err
 	}
-	err = queryer.GetContext(ctx, dest, query, args...)
+	// hook 可以通过 SetContext 传递 span 等上下文
+	execCtx := ec.Context(ctx)
+	if !ec.IsSkip() {
+		// 使用 hook 修改后的 sql 和参数
+		err = queryer.GetContext(execCtx, dest, ec.SQL, ec.Args...)
+	}
 	er := &engine.ExecutorResult{
 		Err:      err,
 		Duration: time.Since(ec.Start),
@@ -186,6 +205,47 @@ func doGet(ctx context.Context, queryer engine.Queryer, dest any, query string,
 	if err == nil {
 		er.QueryRows = 1
 	}
-	hook.After(ctx, ec, er)
+	hook.After(execCtx, ec, er)
+	return err
+}
+
+func doStream(ctx context.Context, queryer engine.Queryer, fn engine.RowsHandler, query string, args []any, hook engine.Hook) error {
+	if hook == nil {
+		return streamRows(ctx, queryer, fn, query, args)
+	}
+	ec := getExecutorContext(ctx, query)
+	ec.Args = args
+	err := hook.Before(ctx, ec)
+	if err != nil {
+		return err
+	}
+	// hook 可以通过 SetContext 传递 span 等上下文
+	execCtx := ec.Context(ctx)
+	var n int64
+	if !ec.IsSkip() {
+		// 使用 hook 修改后的 sql 和参数，耗时包含逐行处理的时间
+		rows, qerr := queryer.QueryContext(execCtx, ec.SQL, ec.Args...)
+		if qerr == nil {
+			n, qerr = fn(rows)
+			_ = rows.Close()
+		}
+		err = qerr
+	}
+	er := &engine.ExecutorResult{
+		Err:       err,
+		Duration:  time.Since(ec.Start),
+		QueryRows: n,
+	}
+	hook.After(execCtx, ec, er)
+	return err
+}
+
+func streamRows(ctx context.Context, queryer engine.Queryer, fn engine.RowsHandler, query string, args []any) error {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	_, err = fn(rows)
 	return err
 }
@@ -0,0 +1,148 @@
+package daox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/engine"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+type rewriteHook struct {
+	comment string
+}
+
+func (h rewriteHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	ec.SQL = "/* " + h.comment + " */ " + ec.SQL
+	return nil
+}
+
+func (h rewriteHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+}
+
+type skipHook struct{}
+
+func (h skipHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	switch ec.Type {
+	case engine.SELECT:
+		if dest, ok := ec.Dest.(*DemoInfo); ok {
+			dest.ID = 1
+			dest.Name = "from-cache"
+		}
+		ec.Skip(nil)
+	default:
+		ec.Skip(engine.NewResult(100, 1))
+	}
+	return nil
+}
+
+func (h skipHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+}
+
+func TestHook_RewriteSQL(t *testing.T) {
+	dbx, mock, err := newMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbx.Close()
+	mock.ExpectExec("^/\\* trace_id=1 \\*/ UPDATE `demo_info` SET `name` = \\?").
+		WithArgs("fengjx", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("^/\\* trace_id=1 \\*/ SELECT `id`, `name` FROM `demo_info`").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "fengjx"))
+
+	dao := daox.NewDao[*DemoInfo]("demo_info", "id",
+		daox.WithDBMaster(dbx),
+		daox.WithHooks(rewriteHook{comment: "trace_id=1"}),
+	)
+	ok, err := dao.UpdateField(1, map[string]any{"name": "fengjx"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	info := &DemoInfo{}
+	exist, err := dao.Selector("id", "name").Where(ql.C(ql.Col("id").EQ(1))).Get(info)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, "fengjx", info.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHook_Skip(t *testing.T) {
+	dbx, mock, err := newMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbx.Close()
+	dao := daox.NewDao[*DemoInfo]("demo_info", "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(dbx),
+		daox.WithHooks(skipHook{}),
+	)
+	id, err := dao.Save(&DemoInfo{Name: "fengjx"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), id)
+
+	info := &DemoInfo{}
+	exist, err := dao.GetByID(1, info)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, "from-cache", info.Name)
+	// 没有实际执行 sql
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// skipOnceHook 第一次执行时跳过，记录每次执行的表名
+type skipOnceHook struct {
+	tables []string
+}
+
+func (h *skipOnceHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	h.tables = append(h.tables, ec.TableName)
+	if len(h.tables) == 1 {
+		ec.Skip(engine.NewResult(0, 100))
+	}
+	return nil
+}
+
+func (h *skipOnceHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+}
+
+func TestHook_ReuseExecutorContext(t *testing.T) {
+	dbx, mock, err := newMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbx.Close()
+	mock.ExpectExec("^UPDATE demo_info SET name = \\?").
+		WithArgs("fengjx").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	hook := &skipOnceHook{}
+	db := daox.NewDb(dbx, hook)
+	parent := &engine.ExecutorContext{
+		Type:      engine.UPDATE,
+		TableName: "custom_table",
+	}
+	ctx := engine.SetExecutorContext(context.Background(), parent)
+	result, err := db.ExecContext(ctx, "UPDATE demo_info SET name = ?", "skip")
+	assert.NoError(t, err)
+	affected, _ := result.RowsAffected()
+	assert.Equal(t, int64(100), affected)
+
+	// 复用执行上下文时保留表名，不会沿用上一次的跳过状态
+	result, err = db.ExecContext(ctx, "UPDATE demo_info SET name = ?", "fengjx")
+	assert.NoError(t, err)
+	affected, _ = result.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+	assert.Equal(t, []string{"custom_table", "custom_table"}, hook.tables)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	// 每次执行复制执行上下文，不会修改上下文中的执行上下文
+	assert.Empty(t, parent.SQL)
+	assert.True(t, parent.Start.IsZero())
+	assert.False(t, parent.IsSkip())
+}
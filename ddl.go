@@ -0,0 +1,422 @@
+package daox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+
+	"github.com/fengjx/daox/utils"
+)
+
+// DDLTagName 字段定义的 tag 名称，eg:
+//
+//	`daox:"type:varchar;size:32;nullable;default:'';index;unique:uni_uid;comment:昵称"`
+const DDLTagName = "daox"
+
+var (
+	ErrColumnMetaRequire  = errors.New("[daox] column meta require, create dao by struct")
+	ErrUnsupportedDialect = errors.New("[daox] unsupported dialect")
+)
+
+// Dialect 数据库方言
+type Dialect string
+
+const (
+	DialectMySQL  Dialect = "mysql"
+	DialectSQLite Dialect = "sqlite3"
+)
+
+// DialectOf 根据数据库驱动名获得方言
+func DialectOf(driverName string) (Dialect, error) {
+	switch driverName {
+	case "mysql":
+		return DialectMySQL, nil
+	case "sqlite3", "sqlite":
+		return DialectSQLite, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedDialect, driverName)
+	}
+}
+
+// ColumnMeta 字段定义
+type ColumnMeta struct {
+	Name       string       // 字段名
+	GoType     reflect.Type // go 类型，没有指定数据库类型时，根据 go 类型推导
+	Type       string       // 数据库类型
+	Size       int          // 字段长度
+	Nullable   bool         // 是否允许为 NULL，指针和 sql.Null* 类型默认允许为 NULL
+	Default    string       // 默认值，原样拼接到 DDL 中
+	HasDefault bool         // 是否设置了默认值
+	Comment    string       // 字段注释
+}
+
+// IndexMeta 索引定义
+type IndexMeta struct {
+	Name    string   // 索引名称
+	Columns []string // 索引字段
+	Unique  bool     // 是否唯一索引
+}
+
+var (
+	timeType    = reflect.TypeFor[time.Time]()
+	scannerType = reflect.TypeFor[sql.Scanner]()
+)
+
+// parseColumnMetas 解析结构体 daox tag，columnNames 为 GetColumnsByType 解析出的字段，嵌入结构体的字段已经展开
+// 相同名称的索引会合并成联合索引，字段顺序与结构体字段顺序一致
+func parseColumnMetas(mapper *reflectx.Mapper, typ reflect.Type, tableName string, columnNames []string) ([]ColumnMeta, []IndexMeta) {
+	structMap := mapper.TypeMap(typ)
+	var (
+		columns  []ColumnMeta
+		indexes  []IndexMeta
+		indexPos = make(map[string]int)
+		addIndex = func(name, column string, unique bool) {
+			if i, ok := indexPos[name]; ok {
+				indexes[i].Columns = append(indexes[i].Columns, column)
+				return
+			}
+			indexPos[name] = len(indexes)
+			indexes = append(indexes, IndexMeta{Name: name, Columns: []string{column}, Unique: unique})
+		}
+	)
+	for _, name := range columnNames {
+		fieldInfo := structMap.GetByPath(name)
+		if fieldInfo == nil {
+			continue
+		}
+		col := ColumnMeta{
+			Name:   fieldInfo.Name,
+			GoType: fieldInfo.Field.Type,
+		}
+		if col.GoType.Kind() == reflect.Pointer {
+			col.Nullable = true
+			col.GoType = col.GoType.Elem()
+		} else if isNullType(col.GoType) {
+			col.Nullable = true
+		}
+		tag := fieldInfo.Field.Tag.Get(DDLTagName)
+		for _, item := range strings.Split(tag, ";") {
+			key, val, _ := strings.Cut(strings.TrimSpace(item), ":")
+			switch strings.ToLower(key) {
+			case "type":
+				col.Type = val
+			case "size":
+				col.Size, _ = strconv.Atoi(val)
+			case "nullable":
+				col.Nullable = val == "" || val == "true"
+			case "not null":
+				col.Nullable = false
+			case "default":
+				col.Default = val
+				col.HasDefault = true
+			case "comment":
+				col.Comment = val
+			case "index":
+				if val == "" {
+					val = defaultIndexName(false, tableName, col.Name)
+				}
+				addIndex(val, col.Name, false)
+			case "unique":
+				if val == "" {
+					val = defaultIndexName(true, tableName, col.Name)
+				}
+				addIndex(val, col.Name, true)
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns, indexes
+}
+
+// defaultIndexName 没有指定索引名称时，使用表名和字段名生成索引名称
+func defaultIndexName(unique bool, tableName, column string) string {
+	if unique {
+		return fmt.Sprintf("uni_%s_%s", tableName, column)
+	}
+	return fmt.Sprintf("idx_%s_%s", tableName, column)
+}
+
+// renameIndexes 修改表名后，使用新的表名重新生成默认的索引名称，避免分表之间索引名称冲突
+func renameIndexes(indexes []IndexMeta, oldTableName, newTableName string) []IndexMeta {
+	if oldTableName == newTableName || len(indexes) == 0 {
+		return indexes
+	}
+	renamed := make([]IndexMeta, len(indexes))
+	for i, index := range indexes {
+		if len(index.Columns) == 1 && index.Name == defaultIndexName(index.Unique, oldTableName, index.Columns[0]) {
+			index.Name = defaultIndexName(index.Unique, newTableName, index.Columns[0])
+		}
+		renamed[i] = index
+	}
+	return renamed
+}
+
+// CreateTableSQL 生成建表语句
+// sqlite 不支持在建表语句中定义索引，索引会生成单独的 CREATE INDEX 语句，所以返回多条语句
+func CreateTableSQL(meta *TableMeta, dialect Dialect) ([]string, error) {
+	if len(meta.ColumnMetas) == 0 {
+		return nil, ErrColumnMetaRequire
+	}
+	if dialect != DialectMySQL && dialect != DialectSQLite {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDialect, dialect)
+	}
+	defs := make([]string, 0, len(meta.ColumnMetas)+len(meta.Indexes)+1)
+	inlinePK := false
+	for _, col := range meta.ColumnMetas {
+		def := columnDefinition(meta, col, dialect)
+		if dialect == DialectSQLite && col.Name == meta.PrimaryKey && meta.IsAutoIncrement {
+			// sqlite 自增主键必须在字段中定义
+			def = fmt.Sprintf("%s INTEGER PRIMARY KEY AUTOINCREMENT", quoteIdent(col.Name, dialect))
+			inlinePK = true
+		}
+		defs = append(defs, def)
+	}
+	if !inlinePK && meta.PrimaryKey != "" {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", quoteIdent(meta.PrimaryKey, dialect)))
+	}
+	var stmts []string
+	if dialect == DialectMySQL {
+		for _, index := range meta.Indexes {
+			keyword := "KEY"
+			if index.Unique {
+				keyword = "UNIQUE KEY"
+			}
+			defs = append(defs, fmt.Sprintf("%s %s (%s)", keyword, quoteIdent(index.Name, dialect), quoteIdents(index.Columns, dialect)))
+		}
+	} else {
+		for _, index := range meta.Indexes {
+			stmts = append(stmts, createIndexSQL(meta.TableName, index, dialect))
+		}
+	}
+	createSQL := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", quoteIdent(meta.TableName, dialect), strings.Join(defs, ",\n  "))
+	return append([]string{createSQL}, stmts...), nil
+}
+
+// AutoMigrate 根据表元信息同步表结构
+// 表不存在时创建表，表存在时只新增缺少的字段和索引，不会修改或删除已有的字段和索引
+// metas 可以通过 dao.TableMeta 获得
+func AutoMigrate(ctx context.Context, db *sqlx.DB, metas ...*TableMeta) error {
+	stmts, err := AutoMigrateSQL(ctx, db, metas...)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err = db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("[daox] exec %s: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// AutoMigrateSQL 对比数据库中的表结构，返回 AutoMigrate 需要执行的语句，不会执行
+func AutoMigrateSQL(ctx context.Context, db *sqlx.DB, metas ...*TableMeta) ([]string, error) {
+	dialect, err := DialectOf(db.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	var stmts []string
+	for _, meta := range metas {
+		columns, err := loadColumns(ctx, db, meta.TableName, dialect)
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			createStmts, err := CreateTableSQL(meta, dialect)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, createStmts...)
+			continue
+		}
+		if len(meta.ColumnMetas) == 0 {
+			return nil, ErrColumnMetaRequire
+		}
+		for _, col := range meta.ColumnMetas {
+			if utils.ContainsString(columns, col.Name) {
+				continue
+			}
+			if dialect == DialectSQLite && !col.Nullable && !col.HasDefault {
+				// sqlite 新增非空字段必须设置默认值
+				col.Default = zeroDefault(col)
+				col.HasDefault = true
+			}
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
+				quoteIdent(meta.TableName, dialect), columnDefinition(meta, col, dialect)))
+		}
+		indexes, err := loadIndexes(ctx, db, meta.TableName, dialect)
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range meta.Indexes {
+			if !utils.ContainsString(indexes, index.Name) {
+				stmts = append(stmts, createIndexSQL(meta.TableName, index, dialect))
+			}
+		}
+	}
+	return stmts, nil
+}
+
+func columnDefinition(meta *TableMeta, col ColumnMeta, dialect Dialect) string {
+	var sb strings.Builder
+	sb.WriteString(quoteIdent(col.Name, dialect))
+	sb.WriteString(" ")
+	sb.WriteString(columnType(col, dialect))
+	if col.Nullable && col.Name != meta.PrimaryKey {
+		sb.WriteString(" NULL")
+	} else {
+		sb.WriteString(" NOT NULL")
+	}
+	if col.Name == meta.PrimaryKey && meta.IsAutoIncrement && dialect == DialectMySQL {
+		sb.WriteString(" AUTO_INCREMENT")
+	}
+	if col.HasDefault {
+		sb.WriteString(" DEFAULT ")
+		sb.WriteString(col.Default)
+	}
+	if col.Comment != "" && dialect == DialectMySQL {
+		sb.WriteString(" COMMENT '")
+		sb.WriteString(strings.ReplaceAll(col.Comment, "'", "''"))
+		sb.WriteString("'")
+	}
+	return sb.String()
+}
+
+// columnType 获得字段的数据库类型，没有通过 tag 指定时根据 go 类型推导
+func columnType(col ColumnMeta, dialect Dialect) string {
+	if col.Type != "" {
+		if col.Size > 0 && !strings.Contains(col.Type, "(") {
+			return fmt.Sprintf("%s(%d)", col.Type, col.Size)
+		}
+		return col.Type
+	}
+	typ := col.GoType
+	if isNullType(typ) {
+		// sql.NullString 等类型使用第一个字段的类型
+		typ = typ.Field(0).Type
+	}
+	if dialect == DialectSQLite {
+		switch typ.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return "INTEGER"
+		case reflect.Float32, reflect.Float64:
+			return "REAL"
+		case reflect.Slice:
+			if typ.Elem().Kind() == reflect.Uint8 {
+				return "BLOB"
+			}
+		case reflect.Struct:
+			if typ == timeType {
+				return "DATETIME"
+			}
+		}
+		return "TEXT"
+	}
+	unsigned := ""
+	switch typ.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		unsigned = " unsigned"
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "tinyint(1)"
+	case reflect.Int8, reflect.Uint8:
+		return "tinyint" + unsigned
+	case reflect.Int16, reflect.Uint16:
+		return "smallint" + unsigned
+	case reflect.Int32, reflect.Uint32:
+		return "int" + unsigned
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "bigint" + unsigned
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		size := col.Size
+		if size <= 0 {
+			size = 255
+		}
+		return fmt.Sprintf("varchar(%d)", size)
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "blob"
+		}
+	case reflect.Struct:
+		if typ == timeType {
+			return "datetime"
+		}
+	}
+	return "json"
+}
+
+// isNullType 是否 sql.NullString、sql.Null[T] 等可以为 NULL 的类型
+func isNullType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.NumField() > 0 &&
+		strings.HasPrefix(typ.Name(), "Null") && reflect.PointerTo(typ).Implements(scannerType)
+}
+
+// zeroDefault 字段类型的零值
+func zeroDefault(col ColumnMeta) string {
+	switch strings.ToUpper(columnType(col, DialectSQLite)) {
+	case "INTEGER", "REAL":
+		return "0"
+	}
+	return "''"
+}
+
+func createIndexSQL(tableName string, index IndexMeta, dialect Dialect) string {
+	keyword := "INDEX"
+	if index.Unique {
+		keyword = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s %s ON %s (%s)", keyword,
+		quoteIdent(index.Name, dialect), quoteIdent(tableName, dialect), quoteIdents(index.Columns, dialect))
+}
+
+func quoteIdent(name string, dialect Dialect) string {
+	if dialect == DialectSQLite {
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func quoteIdents(names []string, dialect Dialect) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdent(name, dialect)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// loadColumns 查询表中已有的字段，表不存在时返回空
+func loadColumns(ctx context.Context, db *sqlx.DB, tableName string, dialect Dialect) ([]string, error) {
+	var columns []string
+	if dialect == DialectMySQL {
+		querySQL := "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?"
+		err := db.SelectContext(ctx, &columns, querySQL, tableName)
+		return columns, err
+	}
+	err := db.SelectContext(ctx, &columns, "SELECT name FROM pragma_table_info(?)", tableName)
+	return columns, err
+}
+
+// loadIndexes 查询表中已有的索引名称
+func loadIndexes(ctx context.Context, db *sqlx.DB, tableName string, dialect Dialect) ([]string, error) {
+	var indexes []string
+	if dialect == DialectMySQL {
+		querySQL := "SELECT DISTINCT index_name FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ?"
+		err := db.SelectContext(ctx, &indexes, querySQL, tableName)
+		return indexes, err
+	}
+	err := db.SelectContext(ctx, &indexes, "SELECT name FROM pragma_index_list(?)", tableName)
+	return indexes, err
+}
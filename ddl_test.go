@@ -0,0 +1,171 @@
+package daox_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+)
+
+type ddlUser struct {
+	ID       int64          `json:"id" daox:"comment:主键"`
+	UID      int64          `json:"uid" daox:"unique:uni_uid"`
+	Nickname string         `json:"nickname" daox:"size:32;default:'';comment:昵称"`
+	Sex      int8           `json:"sex" daox:"default:0;index:idx_sex_age"`
+	Age      uint32         `json:"age" daox:"index:idx_sex_age"`
+	Remark   sql.NullString `json:"remark"`
+	Score    *float64       `json:"score"`
+	Ctime    time.Time      `json:"ctime"`
+}
+
+func (m *ddlUser) GetID() any {
+	return m.ID
+}
+
+func TestCreateTableSQL(t *testing.T) {
+	dao := daox.NewDao[*ddlUser]("ddl_user", "id", daox.IsAutoIncrement())
+	assert.Len(t, dao.TableMeta.ColumnMetas, 8)
+	assert.Equal(t, []daox.IndexMeta{
+		{Name: "uni_uid", Columns: []string{"uid"}, Unique: true},
+		{Name: "idx_sex_age", Columns: []string{"sex", "age"}},
+	}, dao.TableMeta.Indexes)
+
+	stmts, err := daox.CreateTableSQL(dao.TableMeta, daox.DialectMySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CREATE TABLE `ddl_user` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '主键',\n" +
+		"  `uid` bigint NOT NULL,\n" +
+		"  `nickname` varchar(32) NOT NULL DEFAULT '' COMMENT '昵称',\n" +
+		"  `sex` tinyint NOT NULL DEFAULT 0,\n" +
+		"  `age` int unsigned NOT NULL,\n" +
+		"  `remark` varchar(255) NULL,\n" +
+		"  `score` double NULL,\n" +
+		"  `ctime` datetime NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uni_uid` (`uid`),\n" +
+		"  KEY `idx_sex_age` (`sex`, `age`)\n" +
+		")"}, stmts)
+
+	stmts, err = daox.CreateTableSQL(dao.TableMeta, daox.DialectSQLite)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`CREATE TABLE "ddl_user" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "uid" INTEGER NOT NULL,
+  "nickname" TEXT NOT NULL DEFAULT '',
+  "sex" INTEGER NOT NULL DEFAULT 0,
+  "age" INTEGER NOT NULL,
+  "remark" TEXT NULL,
+  "score" REAL NULL,
+  "ctime" DATETIME NOT NULL
+)`,
+		`CREATE UNIQUE INDEX "uni_uid" ON "ddl_user" ("uid")`,
+		`CREATE INDEX "idx_sex_age" ON "ddl_user" ("sex", "age")`,
+	}, stmts)
+
+	_, err = daox.CreateTableSQL(daox.NewDaoByMeta(DemoInfoM{}).TableMeta, daox.DialectMySQL)
+	assert.ErrorIs(t, err, daox.ErrColumnMetaRequire)
+}
+
+type ddlUserV1 struct {
+	ID       int64  `json:"id"`
+	UID      int64  `json:"uid"`
+	Nickname string `json:"nickname"`
+}
+
+func (m *ddlUserV1) GetID() any {
+	return m.ID
+}
+
+func TestAutoMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := newDb()
+	tableName := "ddl_user"
+	after(t, tableName)
+	defer after(t, tableName)
+
+	v1 := daox.NewDao[*ddlUserV1](tableName, "id", daox.IsAutoIncrement(), daox.WithDBMaster(db))
+	assert.NoError(t, daox.AutoMigrate(ctx, db, v1.TableMeta))
+	_, err := v1.Save(&ddlUserV1{UID: 1, Nickname: "u1"})
+	assert.NoError(t, err)
+
+	v2 := daox.NewDao[*ddlUser](tableName, "id", daox.IsAutoIncrement(), daox.WithDBMaster(db))
+	stmts, err := daox.AutoMigrateSQL(ctx, db, v2.TableMeta)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "ddl_user" ADD COLUMN "sex" INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE "ddl_user" ADD COLUMN "age" INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE "ddl_user" ADD COLUMN "remark" TEXT NULL`,
+		`ALTER TABLE "ddl_user" ADD COLUMN "score" REAL NULL`,
+		`ALTER TABLE "ddl_user" ADD COLUMN "ctime" DATETIME NOT NULL DEFAULT ''`,
+		`CREATE UNIQUE INDEX "uni_uid" ON "ddl_user" ("uid")`,
+		`CREATE INDEX "idx_sex_age" ON "ddl_user" ("sex", "age")`,
+	}, stmts)
+	assert.NoError(t, daox.AutoMigrate(ctx, db, v2.TableMeta))
+
+	user := &ddlUser{}
+	exist, err := v2.GetByIDContext(ctx, 1, user)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, "u1", user.Nickname)
+	assert.False(t, user.Remark.Valid)
+
+	// 表结构一致时不需要执行任何语句
+	stmts, err = daox.AutoMigrateSQL(ctx, db, v2.TableMeta)
+	assert.NoError(t, err)
+	assert.Empty(t, stmts)
+
+	_, err = daox.AutoMigrateSQL(ctx, sqlx.NewDb(db.DB, "postgres"), v2.TableMeta)
+	assert.ErrorIs(t, err, daox.ErrUnsupportedDialect)
+}
+
+type ddlBase struct {
+	ID    int64     `json:"id"`
+	Ctime time.Time `json:"ctime"`
+}
+
+type ddlEmbedUser struct {
+	ddlBase
+	Name   string `json:"name" daox:"size:32;index"`
+	Email  string `json:"email" daox:"unique"`
+	Secret string `json:"secret"`
+}
+
+func (m *ddlEmbedUser) GetID() any {
+	return m.ID
+}
+
+func TestCreateTableSQLEmbed(t *testing.T) {
+	dao := daox.NewDao[*ddlEmbedUser]("ddl_embed_user", "id", daox.IsAutoIncrement(), daox.WithOmitColumns("secret"))
+	assert.Equal(t, []string{"id", "ctime", "name", "email"}, dao.TableMeta.Columns)
+	stmts, err := daox.CreateTableSQL(dao.TableMeta, daox.DialectSQLite)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`CREATE TABLE "ddl_embed_user" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "ctime" DATETIME NOT NULL,
+  "name" TEXT NOT NULL,
+  "email" TEXT NOT NULL
+)`,
+		`CREATE INDEX "idx_ddl_embed_user_name" ON "ddl_embed_user" ("name")`,
+		`CREATE UNIQUE INDEX "uni_ddl_embed_user_email" ON "ddl_embed_user" ("email")`,
+	}, stmts)
+
+	// 分表使用新的表名生成默认索引名称，sqlite 中索引名称全局唯一
+	ctx := context.Background()
+	db := newDb()
+	after(t, "ddl_embed_user_0")
+	after(t, "ddl_embed_user_1")
+	defer after(t, "ddl_embed_user_0")
+	defer after(t, "ddl_embed_user_1")
+	meta0 := dao.TableMeta.WithTableName("ddl_embed_user_0")
+	meta1 := dao.TableMeta.WithTableName("ddl_embed_user_1")
+	assert.Equal(t, []daox.IndexMeta{
+		{Name: "idx_ddl_embed_user_1_name", Columns: []string{"name"}},
+		{Name: "uni_ddl_embed_user_1_email", Columns: []string{"email"}, Unique: true},
+	}, meta1.Indexes)
+	assert.NoError(t, daox.AutoMigrate(ctx, db, meta0, meta1))
+}
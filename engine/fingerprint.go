@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	inListRegex     = regexp.MustCompile(`(?i)\b(in)\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	valuesListRegex = regexp.MustCompile(`(?i)\b(values)\s*(\([^()]*\))(?:\s*,\s*\([^()]*\))+`)
+)
+
+// Fingerprint 生成 sql 指纹，用于对同一类 sql 做聚合统计
+// 字面量（字符串、数字，包括负数）替换成 ?，IN 列表和多行 VALUES 折叠成一项，去掉注释、多余空白和末尾分号，统一转成小写
+// eg: SELECT * FROM user WHERE id IN (1, 2, 3) AND name = 'a' -> select * from user where id in (?+) and name = ?
+func Fingerprint(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query))
+	n := len(query)
+	lastSpace := true
+	// last 最后输出的非空白字符，用于判断 - 是否为负号
+	var last byte
+	writeSpace := func() {
+		if !lastSpace {
+			sb.WriteByte(' ')
+			lastSpace = true
+		}
+	}
+	for i := 0; i < n; i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			// 字符串字面量
+			i = skipQuoted(query, i, c)
+			sb.WriteByte('?')
+			last = '?'
+			lastSpace = false
+		case c == '`':
+			// 标识符原样保留
+			end := skipQuoted(query, i, c)
+			sb.WriteString(strings.ToLower(query[i : end+1]))
+			i = end
+			last = '`'
+			lastSpace = false
+		case c == '/' && i+1 < n && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = n
+			} else {
+				i = i + 2 + end + 1
+			}
+			writeSpace()
+		case c == '-' && i+1 < n && query[i+1] == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				i = n
+			} else {
+				i += end
+			}
+			writeSpace()
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			writeSpace()
+		case isDigit(c) && (i == 0 || !isIdentChar(query[i-1])),
+			c == '-' && i+1 < n && isDigit(query[i+1]) && isUnaryPos(last):
+			// 数字字面量，负号与数字合并成一个字面量
+			if c == '-' {
+				i++
+			}
+			for i+1 < n && (isIdentChar(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			sb.WriteByte('?')
+			last = '?'
+			lastSpace = false
+		default:
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			sb.WriteByte(c)
+			last = c
+			lastSpace = false
+		}
+	}
+	fp := strings.TrimSpace(sb.String())
+	fp = strings.TrimSpace(strings.TrimSuffix(fp, ";"))
+	fp = inListRegex.ReplaceAllString(fp, "$1 (?+)")
+	fp = valuesListRegex.ReplaceAllString(fp, "$1 $2")
+	return fp
+}
+
+// isUnaryPos 根据前一个非空白字符判断 - 是否为负号，前面是运算符、括号、逗号或者语句开头时是负号
+func isUnaryPos(last byte) bool {
+	return last == 0 || strings.IndexByte("=<>!(,+-*/%", last) >= 0
+}
+
+// skipQuoted 跳过引号包裹的内容，返回结束引号的位置
+func skipQuoted(s string, start int, quote byte) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			// 连续两个引号表示转义
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(s) - 1
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
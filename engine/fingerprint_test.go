@@ -0,0 +1,54 @@
+package engine
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "literal",
+			sql:  "SELECT * FROM user WHERE id = 10 AND name = 'fengjx' AND score > 1.5;",
+			want: "select * from user where id = ? and name = ? and score > ?",
+		},
+		{
+			name: "in list",
+			sql:  "SELECT `id`, `name` FROM `user` WHERE `id` IN (?, ?, ?);",
+			want: "select `id`, `name` from `user` where `id` in (?+)",
+		},
+		{
+			name: "in list literal",
+			sql:  "select * from user where id in (1,2, 3)",
+			want: "select * from user where id in (?+)",
+		},
+		{
+			name: "values list",
+			sql:  "INSERT INTO `user`(`uid`, `name`) VALUES (?, ?), (?, ?), (?, ?);",
+			want: "insert into `user`(`uid`, `name`) values (?, ?)",
+		},
+		{
+			name: "negative literal",
+			sql:  "SELECT * FROM user WHERE score = -5 AND id IN (-1, 2) AND age > (-3)",
+			want: "select * from user where score = ? and id in (?+) and age > (?)",
+		},
+		{
+			name: "binary minus",
+			sql:  "UPDATE user SET score = score -1, age = age - 2 WHERE id = 1",
+			want: "update user set score = score -?, age = age - ? where id = ?",
+		},
+		{
+			name: "comment and space",
+			sql:  "/* trace_id=1 */ UPDATE  user\n\tSET name = 'it''s' -- comment\n WHERE uid2 = 1",
+			want: "update user set name = ? where uid2 = ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fingerprint(tt.sql); got != tt.want {
+				t.Fatalf("Fingerprint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
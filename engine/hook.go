@@ -2,12 +2,14 @@ package engine
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
 type executorContextKey struct{}
 
 // ExecutorContext SQL 执行器上下文
+// Hook.Before 中可以修改 SQL、Args、NameArgs，实际执行时使用修改后的值
 type ExecutorContext struct {
 	Type      SQLType
 	TableName string
@@ -15,6 +17,79 @@ type ExecutorContext struct {
 	Args      []any
 	NameArgs  any
 	Start     time.Time
+	Dest      any // 查询结果接收对象，仅查询语句有值
+
+	skip   bool
+	result sql.Result
+	values map[any]any
+	ctx    context.Context
+}
+
+// SetValue 保存自定义数据，可以用于在 Hook.Before 和 Hook.After 之间传递数据
+func (ec *ExecutorContext) SetValue(key, val any) {
+	if ec.values == nil {
+		ec.values = make(map[any]any)
+	}
+	ec.values[key] = val
+}
+
+// Value 获取自定义数据
+func (ec *ExecutorContext) Value(key any) any {
+	if ec.values == nil {
+		return nil
+	}
+	return ec.values[key]
+}
+
+// Skip 跳过实际的 sql 执行，在 Hook.Before 中调用，可以用于 mock、缓存等场景
+// 更新语句返回 result，查询语句需要自行填充 Dest，result 传 nil 即可
+func (ec *ExecutorContext) Skip(result sql.Result) {
+	ec.skip = true
+	ec.result = result
+}
+
+// IsSkip 是否跳过实际的 sql 执行
+func (ec *ExecutorContext) IsSkip() bool {
+	return ec.skip
+}
+
+// SkipResult 跳过执行时返回的结果
+func (ec *ExecutorContext) SkipResult() sql.Result {
+	if ec.result == nil {
+		return NewResult(0, 0)
+	}
+	return ec.result
+}
+
+// SetContext 设置执行 sql 使用的上下文，在 Hook.Before 中调用，eg: 链路追踪创建 span 后传递 span 上下文
+func (ec *ExecutorContext) SetContext(ctx context.Context) {
+	ec.ctx = ctx
+}
+
+// Context 执行 sql 使用的上下文，没有通过 SetContext 设置时返回 parent
+func (ec *ExecutorContext) Context(parent context.Context) context.Context {
+	if ec.ctx == nil {
+		return parent
+	}
+	return ec.ctx
+}
+
+// Clone 复制执行上下文，用于每次执行 sql 时使用独立的执行上下文，不会修改原来的执行上下文
+// 复制后清除执行状态、参数和结果，保留类型、表名和自定义数据
+func (ec *ExecutorContext) Clone() *ExecutorContext {
+	cp := &ExecutorContext{
+		Type:      ec.Type,
+		TableName: ec.TableName,
+		SQL:       ec.SQL,
+		Start:     ec.Start,
+	}
+	if len(ec.values) > 0 {
+		cp.values = make(map[any]any, len(ec.values))
+		for k, v := range ec.values {
+			cp.values[k] = v
+		}
+	}
+	return cp
 }
 
 // ExecutorResult 执行结果
@@ -60,11 +135,16 @@ func NewHookChain(hooks ...Hook) *Chain {
 }
 
 // Before 执行前
+// 某个 hook 返回错误时，已经执行过 Before 的 hook 会按相反顺序执行 After，避免 span 等资源泄漏
 func (c *Chain) Before(ctx context.Context, ec *ExecutorContext) error {
 	// 第一个先执行
 	for i := range c.hooks {
-		err := c.hooks[i].Before(ctx, ec)
+		err := c.hooks[i].Before(ec.Context(ctx), ec)
 		if err != nil {
+			er := &ExecutorResult{Err: err, Duration: time.Since(ec.Start)}
+			for j := i - 1; j >= 0; j-- {
+				c.hooks[j].After(ec.Context(ctx), ec, er)
+			}
 			return err
 		}
 	}
@@ -17,3 +17,12 @@ type Queryer interface {
 	// QueryContext 查询多条数据，返回 sql.Rows
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
+
+// RowsHandler 逐行处理查询结果，返回处理的记录行数
+type RowsHandler func(rows *sql.Rows) (int64, error)
+
+// RowsQueryer 流式查询执行器，不会一次性加载全部结果，执行过程会经过 hook
+type RowsQueryer interface {
+	// StreamContext 执行查询并通过 fn 逐行处理结果，fn 返回后关闭 rows
+	StreamContext(ctx context.Context, fn RowsHandler, query string, args ...any) error
+}
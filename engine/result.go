@@ -0,0 +1,27 @@
+package engine
+
+import "database/sql"
+
+// result sql.Result 简单实现
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// NewResult 创建 sql.Result，一般在 Hook 跳过执行时使用
+func NewResult(lastInsertID, rowsAffected int64) sql.Result {
+	return result{
+		lastInsertID: lastInsertID,
+		rowsAffected: rowsAffected,
+	}
+}
+
+// LastInsertId 最后插入的 id
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+// RowsAffected 影响行数
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
@@ -0,0 +1,216 @@
+package daox
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fengjx/daox/engine"
+	"github.com/fengjx/daox/sqlbuilder"
+)
+
+// ExportFormat 导出格式
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"   // 逗号分隔
+	ExportTSV   ExportFormat = "tsv"   // tab 分隔
+	ExportJSONL ExportFormat = "jsonl" // 每行一个 json 对象
+)
+
+// ErrExportFormat 不支持的导出格式
+var ErrExportFormat = errors.New("[daox] unsupported export format")
+
+// Export 导出 QueryRecord 的查询结果，返回导出的记录数
+// 查询前会根据表元信息和访问策略校验字段，没有设置 Page 时导出全部记录
+// queryer 使用 daox.DB、daox.Tx 时会经过 hook，导出过程会出现在慢查询等日志中
+func Export(ctx context.Context, queryer engine.Queryer, query QueryRecord, w io.Writer, opts ...ExportOption) (int64, error) {
+	query, err := query.validated()
+	if err != nil {
+		return 0, err
+	}
+	selector, err := query.buildSelector()
+	if err != nil {
+		return 0, err
+	}
+	return ExportSelector(ctx, selector.Queryer(queryer), w, opts...)
+}
+
+// ExportSelector 导出 Selector 的查询结果，返回导出的记录数
+// 查询结果逐行写入 w，不会一次性加载到内存中，表头为查询的字段名
+func ExportSelector(ctx context.Context, selector *sqlbuilder.Selector, w io.Writer, opts ...ExportOption) (int64, error) {
+	options := &ExportOptions{
+		format:     ExportCSV,
+		header:     true,
+		timeLayout: time.DateTime,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	var count int64
+	err := selector.StreamContext(ctx, func(rows *sql.Rows) (int64, error) {
+		enc, err := newRowEncoder(w, options)
+		if err != nil {
+			return 0, err
+		}
+		scanner, err := newMapScanner(rows, options.scanOpts...)
+		if err != nil {
+			return 0, err
+		}
+		names := make([]string, len(scanner.columns))
+		for i, col := range scanner.columns {
+			names[i] = col.name
+			if name, ok := options.columnNames[col.name]; ok {
+				names[i] = name
+			}
+		}
+		if err = enc.header(names); err != nil {
+			return count, err
+		}
+		for rows.Next() {
+			values, err := scanner.scanValues()
+			if err != nil {
+				return count, err
+			}
+			if err = enc.write(values); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if err = rows.Err(); err != nil {
+			return count, err
+		}
+		return count, enc.flush()
+	})
+	return count, err
+}
+
+// rowEncoder 按格式写入每一行
+type rowEncoder interface {
+	header(names []string) error
+	write(values []any) error
+	flush() error
+}
+
+func newRowEncoder(w io.Writer, options *ExportOptions) (rowEncoder, error) {
+	switch options.format {
+	case ExportCSV, ExportTSV:
+		if options.bom {
+			if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+				return nil, err
+			}
+		}
+		cw := csv.NewWriter(w)
+		if options.format == ExportTSV {
+			cw.Comma = '\t'
+		}
+		return &csvEncoder{w: cw, options: options}, nil
+	case ExportJSONL:
+		return &jsonlEncoder{w: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrExportFormat, options.format)
+	}
+}
+
+type csvEncoder struct {
+	w       *csv.Writer
+	options *ExportOptions
+	record  []string
+}
+
+func (e *csvEncoder) header(names []string) error {
+	e.record = make([]string, len(names))
+	if !e.options.header {
+		return nil
+	}
+	return e.w.Write(names)
+}
+
+func (e *csvEncoder) write(values []any) error {
+	for i, val := range values {
+		s, err := formatExportValue(val, e.options.timeLayout)
+		if err != nil {
+			return err
+		}
+		e.record[i] = s
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlEncoder struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func (e *jsonlEncoder) header(names []string) error {
+	e.keys = make([][]byte, len(names))
+	for i, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		e.keys[i] = key
+	}
+	return nil
+}
+
+// write 按字段顺序输出 json 对象
+func (e *jsonlEncoder) write(values []any) error {
+	_ = e.w.WriteByte('{')
+	for i, val := range values {
+		if i > 0 {
+			_ = e.w.WriteByte(',')
+		}
+		_, _ = e.w.Write(e.keys[i])
+		_ = e.w.WriteByte(':')
+		bs, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		_, _ = e.w.Write(bs)
+	}
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+func (e *jsonlEncoder) flush() error {
+	return e.w.Flush()
+}
+
+// formatExportValue csv、tsv 字段值转换成字符串，NULL 为空字符串，map、slice 等类型输出 json
+func formatExportValue(val any, timeLayout string) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		return v.Format(timeLayout), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	default:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}
+}
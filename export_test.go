@@ -0,0 +1,98 @@
+package daox_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/engine"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+type recordHook struct {
+	ecs []*engine.ExecutorContext
+	ers []*engine.ExecutorResult
+}
+
+func (h *recordHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	return nil
+}
+
+func (h *recordHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+	h.ecs = append(h.ecs, ec)
+	h.ers = append(h.ers, er)
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_export_info"
+	before(t, tableName)
+	hook := &recordHook{}
+	db := daox.NewDb(newDb(), hook)
+	query := daox.QueryRecord{
+		TableName: tableName,
+		Fields:    []string{"id", "name", "sex"},
+		Conditions: []daox.Condition{
+			{ConditionType: daox.ConditionTypeLte, Field: "id", Vals: []any{3}},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := daox.Export(ctx, db, query, &buf,
+		daox.WithExportColumnName("name", "昵称"),
+		daox.WithExportFormatter("sex", func(val any) (any, error) {
+			return strings.ToUpper(val.(string)), nil
+		}),
+		daox.WithExportBOM(),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, "\xEF\xBB\xBFid,昵称,sex\n1,u-0,MALE\n2,u-1,MALE\n3,u-2,MALE\n", buf.String())
+
+	// 经过 hook
+	assert.Len(t, hook.ecs, 1)
+	assert.Equal(t, engine.SELECT, hook.ecs[0].Type)
+	assert.Equal(t, tableName, hook.ecs[0].TableName)
+	assert.Equal(t, int64(3), hook.ers[0].QueryRows)
+
+	buf.Reset()
+	query.Conditions[0].Vals = []any{2}
+	_, err = daox.Export(ctx, db, query, &buf, daox.WithExportFormat(daox.ExportTSV), daox.WithExportHeader(false))
+	assert.NoError(t, err)
+	assert.Equal(t, "1\tu-0\tmale\n2\tu-1\tmale\n", buf.String())
+
+	buf.Reset()
+	_, err = daox.Export(ctx, db, query, &buf, daox.WithExportFormat(daox.ExportJSONL))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"name":"u-0","sex":"male"}`+"\n"+`{"id":2,"name":"u-1","sex":"male"}`+"\n", buf.String())
+
+	_, err = daox.Export(ctx, db, query, &buf, daox.WithExportFormat("xlsx"))
+	assert.ErrorIs(t, err, daox.ErrExportFormat)
+
+	_, err = daox.Export(ctx, db, daox.QueryRecord{TableName: tableName, Fields: []string{"age"}}, &buf)
+	assert.ErrorIs(t, err, daox.ErrUnknownField)
+}
+
+func TestExportSelector(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_export_selector"
+	before(t, tableName)
+	hook := &recordHook{}
+	dao := daox.NewDao[*DemoInfo](tableName, "id", daox.WithDBMaster(newDb()), daox.WithHooks(hook))
+
+	var buf bytes.Buffer
+	n, err := daox.ExportSelector(ctx,
+		dao.Selector("id", "uid").Where(ql.C(ql.Col("uid").GTEQ(108))).OrderBy(ql.Desc("id")),
+		&buf,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, "id,uid\n10,109\n9,108\n", buf.String())
+	assert.Len(t, hook.ecs, 1)
+	assert.Equal(t, fmt.Sprintf("SELECT `id`, `uid` FROM `%s` WHERE `uid` >= ? ORDER BY `id` DESC;", tableName), hook.ecs[0].SQL)
+}
@@ -0,0 +1,331 @@
+package daox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fengjx/daox/types"
+)
+
+// ErrInvalidFilter 查询参数格式错误或者值不能转换成字段类型
+var ErrInvalidFilter = errors.New("[daox] invalid filter")
+
+// 查询参数中的保留字段，其他参数都会解析成查询条件
+const (
+	FilterKeySort   = "sort"   // 排序字段，多个用逗号分隔，- 开头表示降序，eg: sort=-ctime,id
+	FilterKeyLimit  = "limit"  // 每页记录数
+	FilterKeyOffset = "offset" // 游标起始位置
+	FilterKeyFields = "fields" // 投影字段，多个用逗号分隔
+	FilterKeyCount  = "count"  // 是否查询总数
+)
+
+// filterOpSep 字段和条件类型的分隔符，eg: age__gte=18
+const filterOpSep = "__"
+
+// rawFilterTypes 参数保持字符串，不按字段类型转换
+var rawFilterTypes = map[ConditionType]bool{
+	ConditionTypeLike:         true,
+	ConditionTypeNotLike:      true,
+	ConditionTypePrefix:       true,
+	ConditionTypeSuffix:       true,
+	ConditionTypeContains:     true,
+	ConditionTypeRegex:        true,
+	ConditionTypeJSONContains: true,
+}
+
+var filterTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.DateTime,
+	time.DateOnly,
+}
+
+// ParseFilter 把 url 查询参数解析成 QueryRecord，可以直接用于 Find 查询
+// 参数格式为 字段__条件类型=值，没有条件类型时为 eq，eg: ?age__gte=18&name__like=jo&sort=-ctime&limit=20&offset=40
+// in、between 等多个参数的条件使用逗号分隔，参数值会根据表元信息转换成字段类型
+func ParseFilter(tableName string, values url.Values, opts ...FilterOption) (QueryRecord, error) {
+	p, err := newFilterParser(tableName, opts...)
+	if err != nil {
+		return QueryRecord{TableName: tableName}, err
+	}
+	for _, key := range sortedKeys(values) {
+		if err = p.add(key, values[key], true); err != nil {
+			return p.query, err
+		}
+	}
+	return p.build()
+}
+
+// ParseFilterJSON 把 json 对象解析成 QueryRecord，格式与 ParseFilter 一致
+// 多个参数的条件可以使用数组，eg: {"id__in": [1, 2], "sort": "-ctime", "limit": 20}
+func ParseFilterJSON(tableName string, data []byte, opts ...FilterOption) (QueryRecord, error) {
+	p, err := newFilterParser(tableName, opts...)
+	if err != nil {
+		return QueryRecord{TableName: tableName}, err
+	}
+	filter := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&filter); err != nil {
+		return p.query, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	for _, key := range sortedKeys(filter) {
+		val := filter[key]
+		arr, isArr := val.([]any)
+		if !isArr {
+			arr = []any{val}
+		}
+		vals := make([]string, 0, len(arr))
+		for _, item := range arr {
+			s, err := jsonFilterString(item)
+			if err != nil {
+				return p.query, fmt.Errorf("%w: %s: %v", ErrInvalidFilter, key, err)
+			}
+			vals = append(vals, s)
+		}
+		if err = p.add(key, vals, !isArr); err != nil {
+			return p.query, err
+		}
+	}
+	return p.build()
+}
+
+type filterParser struct {
+	validator *fieldValidator
+	columns   map[string]reflect.Type
+	options   *FilterOptions
+	query     QueryRecord
+}
+
+func newFilterParser(tableName string, opts ...FilterOption) (*filterParser, error) {
+	v, err := newFieldValidator(tableName)
+	if err != nil {
+		return nil, err
+	}
+	options := &FilterOptions{defaultLimit: 20, maxLimit: 1000}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &filterParser{
+		validator: v,
+		columns:   v.meta.columnGoTypes(),
+		options:   options,
+		query: QueryRecord{
+			TableName: tableName,
+			Page:      &Page{Limit: options.defaultLimit},
+		},
+	}, nil
+}
+
+// add 解析一个参数，split 为 true 时多个参数的条件按逗号分隔
+func (p *filterParser) add(key string, vals []string, split bool) error {
+	switch key {
+	case FilterKeySort:
+		for _, val := range vals {
+			p.addSort(val)
+		}
+		return nil
+	case FilterKeyFields:
+		for _, val := range vals {
+			p.query.Fields = append(p.query.Fields, splitFilterVal(val)...)
+		}
+		return nil
+	case FilterKeyLimit:
+		return p.setPage(key, vals, &p.query.Page.Limit)
+	case FilterKeyOffset:
+		return p.setPage(key, vals, &p.query.Page.Offset)
+	case FilterKeyCount:
+		count, err := strconv.ParseBool(lastFilterVal(vals))
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidFilter, key, err)
+		}
+		p.query.Page.QueryCount = count
+		return nil
+	}
+	field, conditionType := key, ConditionTypeEq
+	if i := strings.LastIndex(key, filterOpSep); i > 0 {
+		field, conditionType = key[:i], ConditionType(key[i+len(filterOpSep):])
+	}
+	operator, ok := getConditionOperator(conditionType)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrConditionType, key)
+	}
+	if err := p.validator.check(FieldUsageFilter, field); err != nil {
+		return err
+	}
+	if operator.MaxVals == 0 {
+		p.query.Conditions = append(p.query.Conditions, Condition{Op: OpAnd, Field: field, ConditionType: conditionType})
+		return nil
+	}
+	if split && operator.MaxVals != 1 {
+		var splitVals []string
+		for _, val := range vals {
+			splitVals = append(splitVals, splitFilterVal(val)...)
+		}
+		vals = splitVals
+	}
+	if operator.MaxVals == 1 {
+		// 单个参数的条件，参数重复出现时每个值生成一个条件
+		for _, val := range vals {
+			if err := p.addCondition(field, conditionType, []string{val}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return p.addCondition(field, conditionType, vals)
+}
+
+func (p *filterParser) addCondition(field string, conditionType ConditionType, vals []string) error {
+	args := make([]any, 0, len(vals))
+	for _, val := range vals {
+		if rawFilterTypes[conditionType] {
+			args = append(args, val)
+			continue
+		}
+		arg, err := p.coerce(field, val)
+		if err != nil {
+			return fmt.Errorf("%w: %s%s%s=%s: %v", ErrInvalidFilter, field, filterOpSep, conditionType, val, err)
+		}
+		args = append(args, arg)
+	}
+	p.query.Conditions = append(p.query.Conditions, Condition{
+		Op:            OpAnd,
+		Field:         field,
+		Vals:          args,
+		ConditionType: conditionType,
+	})
+	return nil
+}
+
+func (p *filterParser) addSort(val string) {
+	for _, field := range splitFilterVal(val) {
+		orderType := OrderTypeAsc
+		switch field[0] {
+		case '-':
+			orderType = OrderTypeDesc
+			field = field[1:]
+		case '+':
+			field = field[1:]
+		}
+		p.query.OrderFields = append(p.query.OrderFields, OrderField{Field: field, OrderType: orderType})
+	}
+}
+
+func (p *filterParser) setPage(key string, vals []string, dst *int64) error {
+	n, err := strconv.ParseInt(lastFilterVal(vals), 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("%w: %s=%s", ErrInvalidFilter, key, lastFilterVal(vals))
+	}
+	*dst = n
+	return nil
+}
+
+// build 校验排序字段和投影字段，返回解析后的查询参数
+func (p *filterParser) build() (QueryRecord, error) {
+	if p.options.maxLimit > 0 && p.query.Page.Limit > p.options.maxLimit {
+		p.query.Page.Limit = p.options.maxLimit
+	}
+	return p.query.validated()
+}
+
+// coerce 根据字段类型转换参数，没有字段类型时保持字符串
+func (p *filterParser) coerce(field, val string) (any, error) {
+	return coerceString(p.columns[field], val)
+}
+
+// columnGoType 字段的 go 类型，优先使用 daox tag 中定义的数据库类型，没有定义时使用结构体字段类型
+func columnGoType(col ColumnMeta) reflect.Type {
+	if col.Type != "" {
+		sqlType, _, _ := strings.Cut(strings.ToUpper(strings.TrimSpace(col.Type)), "(")
+		return types.SQLType2GolangType(strings.TrimSpace(sqlType))
+	}
+	return col.GoType
+}
+
+// coerceString 把字符串转换成 typ 类型，typ 为 nil 或者不支持的类型时保持字符串
+func coerceString(typ reflect.Type, val string) (any, error) {
+	if typ == nil {
+		return val, nil
+	}
+	if typ == timeType {
+		for _, layout := range filterTimeLayouts {
+			if t, err := time.ParseInLocation(layout, val, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, errors.New("invalid time")
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(n).Convert(typ).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(n).Convert(typ).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(n).Convert(typ).Interface(), nil
+	case reflect.Bool:
+		return strconv.ParseBool(val)
+	default:
+		return val, nil
+	}
+}
+
+func splitFilterVal(val string) []string {
+	var vals []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			vals = append(vals, item)
+		}
+	}
+	return vals
+}
+
+func lastFilterVal(vals []string) string {
+	if len(vals) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(vals[len(vals)-1])
+}
+
+func jsonFilterString(val any) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
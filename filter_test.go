@@ -0,0 +1,123 @@
+package daox_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/sqlbuilder"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+func TestParseFilter(t *testing.T) {
+	tableName := "test_parse_filter"
+	before(t, tableName)
+	values, err := url.ParseQuery("uid__gte=105&name__prefix=u-&id__in=1,6,8&sex=male&sort=-uid,id&limit=2&offset=1&count=true")
+	assert.NoError(t, err)
+	query, err := daox.ParseFilter(tableName, values)
+	assert.NoError(t, err)
+	assert.Equal(t, []daox.Condition{
+		{Op: daox.OpAnd, Field: "id", Vals: []any{int64(1), int64(6), int64(8)}, ConditionType: daox.ConditionTypeIn},
+		{Op: daox.OpAnd, Field: "name", Vals: []any{"u-"}, ConditionType: daox.ConditionTypePrefix},
+		{Op: daox.OpAnd, Field: "sex", Vals: []any{"male"}, ConditionType: daox.ConditionTypeEq},
+		{Op: daox.OpAnd, Field: "uid", Vals: []any{int64(105)}, ConditionType: daox.ConditionTypeGte},
+	}, query.Conditions)
+	assert.Equal(t, []daox.OrderField{
+		{Field: "uid", OrderType: daox.OrderTypeDesc},
+		{Field: "id", OrderType: daox.OrderTypeAsc},
+	}, query.OrderFields)
+	assert.Equal(t, &daox.Page{Limit: 2, Offset: 1, QueryCount: true}, query.Page)
+
+	list, page, err := daox.Find[DemoInfo](context.Background(), newDb(), query)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(6), list[0].ID)
+	assert.Equal(t, int64(2), page.Count)
+
+	query, err = daox.ParseFilterJSON(tableName, []byte(`{"id__between": [2, 4], "name__is_null": null, "fields": "id,name", "limit": 5000}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, query.Fields)
+	assert.Equal(t, []daox.Condition{
+		{Op: daox.OpAnd, Field: "id", Vals: []any{int64(2), int64(4)}, ConditionType: daox.ConditionTypeBetween},
+		{Op: daox.OpAnd, Field: "name", ConditionType: daox.ConditionTypeIsNull},
+	}, query.Conditions)
+	assert.Equal(t, int64(1000), query.Page.Limit)
+
+	query, err = daox.ParseFilter(tableName, url.Values{}, daox.WithDefaultLimit(5))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), query.Page.Limit)
+}
+
+// filterUserM 与代码生成器生成的 meta 结构一致
+type filterUserM struct {
+	ID   sqlbuilder.TypedCol[int64]
+	Name sqlbuilder.TypedCol[string]
+	Age  sqlbuilder.TypedCol[int32]
+}
+
+func (m filterUserM) TableName() string {
+	return "filter_meta_user"
+}
+
+func (m filterUserM) PrimaryKey() string {
+	return "id"
+}
+
+func (m filterUserM) IsAutoIncrement() bool {
+	return true
+}
+
+func (m filterUserM) Columns() []string {
+	return []string{"id", "name", "age"}
+}
+
+func TestParseFilterByMeta(t *testing.T) {
+	// NewDaoByMeta 创建的 dao 没有 ColumnMetas，使用 meta 中 TypedCol 的类型转换参数
+	daox.NewDaoByMeta(filterUserM{
+		ID:   ql.Typed[int64]("id"),
+		Name: ql.Typed[string]("name"),
+		Age:  ql.Typed[int32]("age"),
+	})
+	values, err := url.ParseQuery("age__gte=18&name=18&id__in=1,2")
+	assert.NoError(t, err)
+	query, err := daox.ParseFilter("filter_meta_user", values)
+	assert.NoError(t, err)
+	assert.Equal(t, []daox.Condition{
+		{Op: daox.OpAnd, Field: "age", Vals: []any{int32(18)}, ConditionType: daox.ConditionTypeGte},
+		{Op: daox.OpAnd, Field: "id", Vals: []any{int64(1), int64(2)}, ConditionType: daox.ConditionTypeIn},
+		{Op: daox.OpAnd, Field: "name", Vals: []any{"18"}, ConditionType: daox.ConditionTypeEq},
+	}, query.Conditions)
+
+	_, err = daox.ParseFilter("filter_meta_user", url.Values{"age": {"abc"}})
+	assert.Error(t, err)
+}
+
+func TestParseFilterError(t *testing.T) {
+	tableName := "test_parse_filter_error"
+	before(t, tableName)
+	testCases := []struct {
+		name    string
+		query   string
+		wantErr error
+	}{
+		{name: "unknown field", query: "age__gte=18", wantErr: daox.ErrUnknownField},
+		{name: "unknown sort field", query: "sort=-age", wantErr: daox.ErrUnknownField},
+		{name: "unknown condition type", query: "uid__near=1", wantErr: daox.ErrConditionType},
+		{name: "invalid value", query: "uid__gte=abc", wantErr: daox.ErrInvalidFilter},
+		{name: "invalid limit", query: "limit=-1", wantErr: daox.ErrInvalidFilter},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			assert.NoError(t, err)
+			_, err = daox.ParseFilter(tableName, values)
+			assert.True(t, errors.Is(err, tc.wantErr), err)
+		})
+	}
+	_, err := daox.ParseFilter("not_registered", url.Values{})
+	assert.ErrorIs(t, err, daox.ErrTableNotRegistered)
+}
@@ -12,7 +12,8 @@ var global *globalConfig
 
 func init() {
 	global = &globalConfig{
-		metaMap: make(map[string]*TableMeta),
+		metaMap:   make(map[string]*TableMeta),
+		policyMap: make(map[string]TablePolicy),
 	}
 }
 
@@ -25,6 +26,8 @@ type globalConfig struct {
 	defaultReadDB *sqlx.DB
 	// 所有表元信息
 	metaMap map[string]*TableMeta
+	// 表字段访问策略
+	policyMap map[string]TablePolicy
 	// 保存时默认忽略的字段，全局生效
 	// 一般用户统一的开发规范
 	omitColumns []string
@@ -67,6 +70,11 @@ func UseOmits(omits ...string) {
 	global.omitColumns = append(global.omitColumns, omits...)
 }
 
+// UseTableMeta 注册表元信息，没有通过 NewDao 创建 dao 时使用，eg: 命令行工具根据数据库表结构注册
+func UseTableMeta(meta TableMeta) {
+	global.registerMeta(&meta)
+}
+
 // GetMetaInfo 根据表名获得元信息
 func GetMetaInfo(tableName string) (TableMeta, bool) {
 	meta, ok := global.metaMap[tableName]
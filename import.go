@@ -0,0 +1,319 @@
+package daox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fengjx/daox/engine"
+	"github.com/fengjx/daox/utils"
+)
+
+// ErrImportFailed 事务导入时存在失败的行，已经全部回滚
+var ErrImportFailed = errors.New("[daox] import failed")
+
+// LineError 导入失败的行
+type LineError struct {
+	Line int // 文件中的行号，从 1 开始
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Lines    int64        // 读取的数据行数，不包含表头和空行
+	Affected int64        // 影响行数，事务回滚时为 0
+	Errors   []*LineError // 失败的行
+}
+
+// Import 从 csv、tsv、jsonl 导入数据到已注册元信息的表，通过 BatchInsert 分批插入
+// csv、tsv 第一行为表头，表头和 json key 需要是表中的字段，可以通过 WithImportColumnName 设置映射关系
+// 字段值会根据表元信息转换成字段类型，非字符串字段的空值插入 NULL
+// 格式错误、类型转换失败、插入失败的行记录在 ImportResult.Errors 中，其他行继续导入
+// 使用 WithImportTx 时有任意一行失败都会回滚，返回 ErrImportFailed
+func Import(ctx context.Context, db *sqlx.DB, tableName string, r io.Reader, opts ...ImportOption) (*ImportResult, error) {
+	meta, ok := GetMetaInfo(tableName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotRegistered, tableName)
+	}
+	options := &ImportOptions{format: ExportCSV, batchSize: 500}
+	for _, opt := range opts {
+		opt(options)
+	}
+	reader, err := newRowReader(r, meta, options)
+	if err != nil {
+		return nil, err
+	}
+	im := &importer{meta: meta, reader: reader, options: options, result: &ImportResult{}}
+	if !options.tx {
+		return im.result, im.run(ctx, NewDb(db, global.hooks...))
+	}
+	err = NewTxManager(db).ExecTx(ctx, func(txCtx context.Context, executor engine.Executor) error {
+		if err := im.run(txCtx, executor); err != nil {
+			return err
+		}
+		if len(im.result.Errors) > 0 {
+			return fmt.Errorf("%w: %d lines failed", ErrImportFailed, len(im.result.Errors))
+		}
+		return nil
+	})
+	if err != nil {
+		im.result.Affected = 0
+	}
+	return im.result, err
+}
+
+type importer struct {
+	meta    TableMeta
+	reader  rowReader
+	options *ImportOptions
+	result  *ImportResult
+	rows    []map[string]any
+	lines   []int
+}
+
+func (im *importer) run(ctx context.Context, execer engine.Execer) error {
+	// 限制每批的行数不超过数据库参数个数限制，保证每批只执行一条语句，逐行重试时不会重复插入已经成功的行
+	batchSize := im.options.batchSize
+	if limit := maxPlaceholders(dialectOfExecer(execer)) / max(len(im.meta.Columns), 1); batchSize <= 0 || batchSize > limit {
+		batchSize = limit
+	}
+	for {
+		line, row, err := im.reader.next()
+		if err == io.EOF {
+			break
+		}
+		var lineErr *LineError
+		if errors.As(err, &lineErr) {
+			im.result.Lines++
+			im.result.Errors = append(im.result.Errors, lineErr)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		im.result.Lines++
+		im.rows = append(im.rows, row)
+		im.lines = append(im.lines, line)
+		if len(im.rows) >= batchSize {
+			if err = im.flush(ctx, execer); err != nil {
+				return err
+			}
+		}
+	}
+	return im.flush(ctx, execer)
+}
+
+// flush 插入缓存的行，字段相同的行一起插入，每组只有一条语句，批量插入失败时整组没有写入，逐行重试找出失败的行
+// 每行插入的字段只由行本身决定，与批次大小和同一批的其他行无关
+func (im *importer) flush(ctx context.Context, execer engine.Execer) error {
+	if len(im.rows) == 0 {
+		return nil
+	}
+	defer func() {
+		im.rows = im.rows[:0]
+		im.lines = im.lines[:0]
+	}()
+	for _, group := range groupRowsByColumns(im.rows) {
+		affected, err := im.insert(ctx, execer, group.rows)
+		if err == nil {
+			im.result.Affected += affected
+			continue
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		for i, row := range group.rows {
+			affected, err = im.insert(ctx, execer, []map[string]any{row})
+			if err != nil {
+				im.result.Errors = append(im.result.Errors, &LineError{Line: im.lines[group.indexes[i]], Err: err})
+				continue
+			}
+			im.result.Affected += affected
+		}
+	}
+	return nil
+}
+
+func (im *importer) insert(ctx context.Context, execer engine.Execer, rows []map[string]any) (int64, error) {
+	opts := append([]BatchInsertOption{WithBatchSize(len(rows))}, im.options.batchOpts...)
+	return BatchInsert(ctx, execer, BatchInsertRecord{TableName: im.meta.TableName, Rows: rows}, opts...)
+}
+
+// rowReader 逐行读取导入数据，返回 io.EOF 表示读取结束，返回 *LineError 表示当前行解析失败
+type rowReader interface {
+	next() (line int, row map[string]any, err error)
+}
+
+func newRowReader(r io.Reader, meta TableMeta, options *ImportOptions) (rowReader, error) {
+	base := rowConverter{meta: meta, types: meta.columnGoTypes(), names: options.columnNames}
+	switch options.format {
+	case ExportCSV, ExportTSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		if options.format == ExportTSV {
+			cr.Comma = '\t'
+			cr.LazyQuotes = true
+		}
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("[daox] read header: %w", err)
+		}
+		fields := make([]string, len(header))
+		for i, name := range header {
+			if i == 0 {
+				name = strings.TrimPrefix(name, "\xEF\xBB\xBF")
+			}
+			if fields[i], err = base.column(name); err != nil {
+				return nil, err
+			}
+		}
+		return &csvRowReader{rowConverter: base, r: cr, fields: fields}, nil
+	case ExportJSONL:
+		return &jsonlRowReader{rowConverter: base, r: bufio.NewReader(r)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrExportFormat, options.format)
+	}
+}
+
+// rowConverter 把导入的字段名和值转换成表字段和字段类型
+type rowConverter struct {
+	meta  TableMeta
+	types map[string]reflect.Type
+	names map[string]string
+}
+
+func (c rowConverter) column(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if column, ok := c.names[name]; ok {
+		name = column
+	}
+	if !utils.ContainsString(c.meta.Columns, name) {
+		return "", fmt.Errorf("%w: %s.%s", ErrUnknownField, c.meta.TableName, name)
+	}
+	return name, nil
+}
+
+// convert 字符串转换成字段类型，没有字段类型时保持字符串，非字符串字段的空值转换成 nil
+func (c rowConverter) convert(column, val string) (any, error) {
+	typ := c.types[column]
+	if val == "" && typ != nil && typ.Kind() != reflect.String {
+		return nil, nil
+	}
+	res, err := coerceString(typ, val)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %w", column, err)
+	}
+	return res, nil
+}
+
+type csvRowReader struct {
+	rowConverter
+	r      *csv.Reader
+	fields []string
+}
+
+func (cr *csvRowReader) next() (int, map[string]any, error) {
+	record, err := cr.r.Read()
+	if err == io.EOF {
+		return 0, nil, err
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine, nil, &LineError{Line: parseErr.StartLine, Err: parseErr.Err}
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	line, _ := cr.r.FieldPos(0)
+	if len(record) != len(cr.fields) {
+		return line, nil, &LineError{Line: line, Err: fmt.Errorf("expect %d fields, got %d", len(cr.fields), len(record))}
+	}
+	row := make(map[string]any, len(record))
+	for i, val := range record {
+		if row[cr.fields[i]], err = cr.convert(cr.fields[i], val); err != nil {
+			return line, nil, &LineError{Line: line, Err: err}
+		}
+	}
+	return line, row, nil
+}
+
+type jsonlRowReader struct {
+	rowConverter
+	r    *bufio.Reader
+	line int
+}
+
+func (jr *jsonlRowReader) next() (int, map[string]any, error) {
+	for {
+		data, err := jr.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return 0, nil, err
+		}
+		if len(data) == 0 && err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		jr.line++
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		row, convErr := jr.parse(data)
+		if convErr != nil {
+			return jr.line, nil, &LineError{Line: jr.line, Err: convErr}
+		}
+		return jr.line, row, nil
+	}
+}
+
+func (jr *jsonlRowReader) parse(data []byte) (map[string]any, error) {
+	obj := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	row := make(map[string]any, len(obj))
+	for name, val := range obj {
+		column, err := jr.column(name)
+		if err != nil {
+			return nil, err
+		}
+		switch v := val.(type) {
+		case nil, bool:
+			row[column] = v
+		case string:
+			if row[column], err = jr.convert(column, v); err != nil {
+				return nil, err
+			}
+		case json.Number:
+			if row[column], err = jr.convert(column, v.String()); err != nil {
+				return nil, err
+			}
+		default:
+			// 对象和数组保存为 json 字符串
+			bs, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			row[column] = string(bs)
+		}
+	}
+	return row, nil
+}
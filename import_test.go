@@ -0,0 +1,189 @@
+package daox_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+func countRows(t *testing.T, tableName string) int64 {
+	var count int64
+	assert.NoError(t, newDb().Get(&count, fmt.Sprintf("SELECT count(*) FROM %s", tableName)))
+	return count
+}
+
+func TestImportCSV(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_import_csv"
+	before(t, tableName)
+	data := "\xEF\xBB\xBFuid,昵称,sex,login_time\n" +
+		"1001,i-1,male,\n" +
+		"abc,i-2,male,1\n" +
+		"1003,i-3\n" +
+		"1004,\"i-4\nline\",female,2\n"
+	result, err := daox.Import(ctx, newDb(), tableName, strings.NewReader(data),
+		daox.WithImportColumnName("昵称", "name"),
+		daox.WithImportBatchSize(2),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.Lines)
+	assert.Equal(t, int64(2), result.Affected)
+	assert.Len(t, result.Errors, 2)
+	assert.Equal(t, 3, result.Errors[0].Line)
+	assert.Equal(t, 4, result.Errors[1].Line)
+	assert.Equal(t, int64(12), countRows(t, tableName))
+
+	var names []string
+	assert.NoError(t, newDb().Select(&names, fmt.Sprintf("SELECT name FROM %s WHERE uid > 1000 AND login_time IS NULL", tableName)))
+	assert.Equal(t, []string{"i-1"}, names)
+
+	// 主键冲突的行单独记录错误
+	result, err = daox.Import(ctx, newDb(), tableName, strings.NewReader("id,uid\n1,1\n100,100\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Affected)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 2, result.Errors[0].Line)
+
+	// 批量大小超过参数个数限制时，每批仍然只执行一条语句，重试时不会重复插入已经成功的行
+	var sb strings.Builder
+	sb.WriteString("id,uid\n")
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&sb, "%d,%d\n", 10000+i, 10000+i)
+	}
+	sb.WriteString("1,1\n")
+	result, err = daox.Import(ctx, newDb(), tableName, strings.NewReader(sb.String()),
+		daox.WithImportBatchSize(30000),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20000), result.Affected)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 20002, result.Errors[0].Line)
+
+	_, err = daox.Import(ctx, newDb(), tableName, strings.NewReader("uid,age\n1,2\n"))
+	assert.ErrorIs(t, err, daox.ErrUnknownField)
+
+	_, err = daox.Import(ctx, newDb(), "not_registered", strings.NewReader(""))
+	assert.ErrorIs(t, err, daox.ErrTableNotRegistered)
+}
+
+func TestImportTx(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_import_tx"
+	before(t, tableName)
+	data := `{"uid": 2001, "name": "j-1"}
+{"uid": "x", "name": "j-2"}
+`
+	result, err := daox.Import(ctx, newDb(), tableName, strings.NewReader(data),
+		daox.WithImportFormat(daox.ExportJSONL),
+		daox.WithImportTx(),
+	)
+	assert.ErrorIs(t, err, daox.ErrImportFailed)
+	assert.Equal(t, int64(0), result.Affected)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 2, result.Errors[0].Line)
+	assert.Equal(t, int64(10), countRows(t, tableName))
+
+	data = `{"id": 1, "uid": 1, "name": "j-1"}
+
+{"id": 100, "uid": 100, "name": {"a": 1}}
+`
+	result, err = daox.Import(ctx, newDb(), tableName, strings.NewReader(data),
+		daox.WithImportFormat(daox.ExportJSONL),
+		daox.WithImportBatchOptions(daox.WithUpsert(nil, "name")),
+		daox.WithImportTx(),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Lines)
+	assert.Empty(t, result.Errors)
+	var names []string
+	assert.NoError(t, newDb().Select(&names, fmt.Sprintf("SELECT name FROM %s WHERE id IN (1, 100) ORDER BY id", tableName)))
+	assert.Equal(t, []string{"j-1", `{"a":1}`}, names)
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	from, to := "test_export_from", "test_import_to"
+	before(t, from)
+	before(t, to)
+	var buf bytes.Buffer
+	_, err := daox.Export(ctx, newDb(), daox.QueryRecord{TableName: from}, &buf, daox.WithExportFormat(daox.ExportTSV))
+	assert.NoError(t, err)
+	result, err := daox.Import(ctx, newDb(), to, &buf,
+		daox.WithImportFormat(daox.ExportTSV),
+		daox.WithImportBatchOptions(daox.WithConflictPolicy(daox.ConflictReplace)),
+	)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, int64(10), result.Lines)
+	assert.Equal(t, int64(10), countRows(t, to))
+}
+
+func TestImportByMeta(t *testing.T) {
+	ctx := context.Background()
+	tableName := "filter_meta_user"
+	after(t, tableName)
+	defer after(t, tableName)
+	db := newDb()
+	_, err := db.Exec("CREATE TABLE filter_meta_user (id integer primary key autoincrement, name text, age integer)")
+	assert.NoError(t, err)
+	// NewDaoByMeta 创建的 dao 没有 ColumnMetas，使用 meta 中 TypedCol 的类型转换字段值
+	daox.NewDaoByMeta(filterUserM{
+		ID:   ql.Typed[int64]("id"),
+		Name: ql.Typed[string]("name"),
+		Age:  ql.Typed[int32]("age"),
+	})
+	result, err := daox.Import(ctx, db, tableName, strings.NewReader("name,age\n18,18\nu2,abc\nu3,\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Affected)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 3, result.Errors[0].Line)
+
+	var rows []struct {
+		Name string        `db:"name"`
+		Age  sql.NullInt64 `db:"age"`
+	}
+	assert.NoError(t, db.Select(&rows, "SELECT name, age FROM filter_meta_user ORDER BY id"))
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "18", rows[0].Name)
+	assert.Equal(t, sql.NullInt64{Int64: 18, Valid: true}, rows[0].Age)
+	assert.False(t, rows[1].Age.Valid)
+}
+
+func TestImportJSONLColumns(t *testing.T) {
+	ctx := context.Background()
+	tableName := "filter_meta_user"
+	daox.NewDaoByMeta(filterUserM{
+		ID:   ql.Typed[int64]("id"),
+		Name: ql.Typed[string]("name"),
+		Age:  ql.Typed[int32]("age"),
+	})
+	data := `{"name": "j-1", "age": 1}
+{"name": "j-2"}
+`
+	// 插入的字段与批次大小无关，没有的字段使用表的默认值
+	for _, batchSize := range []int{1, 10} {
+		after(t, tableName)
+		db := newDb()
+		_, err := db.Exec("CREATE TABLE filter_meta_user (id integer primary key autoincrement, name text, age integer NOT NULL DEFAULT 7)")
+		assert.NoError(t, err)
+		result, err := daox.Import(ctx, db, tableName, strings.NewReader(data),
+			daox.WithImportFormat(daox.ExportJSONL),
+			daox.WithImportBatchSize(batchSize),
+		)
+		assert.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, int64(2), result.Affected)
+		var ages []int
+		assert.NoError(t, db.Select(&ages, "SELECT age FROM filter_meta_user ORDER BY id"))
+		assert.Equal(t, []int{1, 7}, ages)
+	}
+	after(t, tableName)
+}
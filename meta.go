@@ -1,6 +1,8 @@
 package daox
 
 import (
+	"reflect"
+
 	"github.com/fengjx/daox/utils"
 )
 
@@ -10,6 +12,13 @@ type TableMeta struct {
 	Columns         []string // 表字段列表
 	PrimaryKey      string   // 主键字段名
 	IsAutoIncrement bool     // 主键是否自增
+	// ColumnMetas 字段定义，通过结构体 daox tag 解析，用于生成 DDL
+	ColumnMetas []ColumnMeta
+	// Indexes 索引定义，通过结构体 daox tag 解析，用于生成 DDL
+	Indexes []IndexMeta
+	// ColumnTypes 字段的 go 类型，NewDaoByMeta 根据 meta 中 sqlbuilder.TypedCol 类型的字段解析
+	// 没有 ColumnMetas 时用于查询条件、导入数据的类型转换
+	ColumnTypes map[string]reflect.Type
 }
 
 // OmitColumns 获取排除指定字段后的字段列表
@@ -36,7 +45,53 @@ func (meta TableMeta) WithTableName(tableName string) *TableMeta {
 		Columns:         meta.Columns,
 		PrimaryKey:      meta.PrimaryKey,
 		IsAutoIncrement: meta.IsAutoIncrement,
+		ColumnMetas:     meta.ColumnMetas,
+		Indexes:         renameIndexes(meta.Indexes, meta.TableName, tableName),
+		ColumnTypes:     meta.ColumnTypes,
+	}
+}
+
+// columnGoTypes 字段的 go 类型，优先使用 ColumnMetas 中的定义，没有定义的字段使用 ColumnTypes
+func (meta TableMeta) columnGoTypes() map[string]reflect.Type {
+	types := make(map[string]reflect.Type, len(meta.Columns))
+	for name, typ := range meta.ColumnTypes {
+		types[name] = typ
+	}
+	for _, col := range meta.ColumnMetas {
+		if typ := columnGoType(col); typ != nil {
+			types[col.Name] = typ
+		}
+	}
+	return types
+}
+
+// typedColumn sqlbuilder.TypedCol 实现的接口
+type typedColumn interface {
+	Name() string
+	GoType() reflect.Type
+}
+
+// parseColumnTypes 解析生成的 meta 结构体中 sqlbuilder.TypedCol 类型字段的 go 类型
+func parseColumnTypes(m Meta) map[string]reflect.Type {
+	v := reflect.Indirect(reflect.ValueOf(m))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var types map[string]reflect.Type
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		col, ok := v.Field(i).Interface().(typedColumn)
+		if !ok || col.Name() == "" {
+			continue
+		}
+		if types == nil {
+			types = make(map[string]reflect.Type)
+		}
+		types[col.Name()] = col.GoType()
 	}
+	return types
 }
 
 // Model 数据库模型接口，所有数据库模型结构体都需要实现此接口
@@ -0,0 +1,65 @@
+package daox
+
+import (
+	"context"
+
+	"github.com/fengjx/daox/engine"
+)
+
+// 指标标签名
+const (
+	LabelSQLType = "sql_type"
+	LabelTable   = "table"
+)
+
+// Label 指标标签
+type Label struct {
+	Key   string
+	Value string
+}
+
+// Histogram 直方图指标，用于统计耗时分布
+type Histogram interface {
+	// Record 记录一个观测值
+	Record(ctx context.Context, value float64, labels ...Label)
+}
+
+// Counter 计数器指标
+type Counter interface {
+	// Add 计数增加
+	Add(ctx context.Context, delta int64, labels ...Label)
+}
+
+// MetricsHook 指标统计中间件，按 sql 类型和表名统计耗时和异常数
+type MetricsHook struct {
+	latency Histogram
+	errors  Counter
+}
+
+// NewMetricsHook 创建指标统计中间件
+// latency 执行耗时（单位：秒），errors 执行异常次数，传 nil 表示不统计
+func NewMetricsHook(latency Histogram, errors Counter) *MetricsHook {
+	return &MetricsHook{
+		latency: latency,
+		errors:  errors,
+	}
+}
+
+// Before 执行前
+func (h *MetricsHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	return nil
+}
+
+// After 执行后
+func (h *MetricsHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+	labels := []Label{
+		{Key: LabelSQLType, Value: string(ec.Type)},
+		{Key: LabelTable, Value: tableNameOf(ec)},
+	}
+	if h.latency != nil {
+		h.latency.Record(ctx, er.Duration.Seconds(), labels...)
+	}
+	if h.errors != nil && er.Err != nil {
+		h.errors.Add(ctx, 1, labels...)
+	}
+}
@@ -0,0 +1,65 @@
+package daox_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+)
+
+// memMetric 内存指标，按标签汇总
+type memMetric struct {
+	mu     sync.Mutex
+	values map[string][]float64
+}
+
+func newMemMetric() *memMetric {
+	return &memMetric{values: map[string][]float64{}}
+}
+
+func (m *memMetric) key(labels []daox.Label) string {
+	var parts []string
+	for _, label := range labels {
+		parts = append(parts, label.Key+"="+label.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (m *memMetric) Record(ctx context.Context, value float64, labels ...daox.Label) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k := m.key(labels)
+	m.values[k] = append(m.values[k], value)
+}
+
+func (m *memMetric) Add(ctx context.Context, delta int64, labels ...daox.Label) {
+	m.Record(ctx, float64(delta), labels...)
+}
+
+func TestMetricsHook(t *testing.T) {
+	tableName := "demo_info_metrics"
+	before(t, tableName)
+	latency := newMemMetric()
+	errs := newMemMetric()
+	dao := daox.NewDao[*DemoInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(newDb()),
+		daox.WithHooks(daox.NewMetricsHook(latency, errs)),
+	)
+	info := &DemoInfo{}
+	_, err := dao.GetByID(1, info)
+	assert.NoError(t, err)
+	_, err = dao.GetByID(2, info)
+	assert.NoError(t, err)
+	_, err = dao.DeleteByColumn(daox.OfKv("not_exist", 1))
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, len(latency.values["sql_type=SELECT,table="+tableName]))
+	assert.Equal(t, 1, len(latency.values["sql_type=DELETE,table="+tableName]))
+	assert.Equal(t, []float64{1}, errs.values["sql_type=DELETE,table="+tableName])
+	assert.Equal(t, 1, len(errs.values))
+}
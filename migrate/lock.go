@@ -0,0 +1,190 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Locker 迁移锁，防止多个实例同时执行迁移
+type Locker interface {
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+}
+
+// DefaultLockTTL 锁表中锁的默认过期时间
+const DefaultLockTTL = 10 * time.Minute
+
+// NewLocker 根据数据库驱动创建迁移锁
+// mysql 使用 GET_LOCK，postgres 使用 pg_advisory_lock，其他数据库使用锁表，锁表的过期时间为 DefaultLockTTL
+func NewLocker(db *sqlx.DB, name string, timeout time.Duration) Locker {
+	return newLocker(db, name, timeout, DefaultLockTTL)
+}
+
+func newLocker(db *sqlx.DB, name string, timeout, ttl time.Duration) Locker {
+	switch db.DriverName() {
+	case "mysql":
+		return &mysqlLocker{db: db, name: name, timeout: timeout}
+	case "postgres", "pgx":
+		return &pgLocker{db: db, name: name, timeout: timeout}
+	default:
+		return NewTableLocker(db, name+"_lock", timeout, ttl)
+	}
+}
+
+// mysqlLocker 基于 GET_LOCK 实现的锁，锁与连接绑定
+type mysqlLocker struct {
+	db      *sqlx.DB
+	name    string
+	timeout time.Duration
+	conn    *sql.Conn
+}
+
+func (l *mysqlLocker) Lock(ctx context.Context) error {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	var ok sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", l.name, int(l.timeout.Seconds())).Scan(&ok)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if ok.Int64 != 1 {
+		_ = conn.Close()
+		return ErrLocked
+	}
+	l.conn = conn
+	return nil
+}
+
+func (l *mysqlLocker) Unlock(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+	defer func() {
+		_ = l.conn.Close()
+		l.conn = nil
+	}()
+	_, err := l.conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", l.name)
+	return err
+}
+
+// pgLocker 基于 pg_try_advisory_lock 实现的锁，锁与连接绑定
+type pgLocker struct {
+	db      *sqlx.DB
+	name    string
+	timeout time.Duration
+	conn    *sql.Conn
+}
+
+func (l *pgLocker) key() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(l.name))
+	return int64(h.Sum64())
+}
+
+func (l *pgLocker) Lock(ctx context.Context) error {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	err = retry(ctx, l.timeout, func() (bool, error) {
+		var ok bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key()).Scan(&ok)
+		return ok, err
+	})
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	l.conn = conn
+	return nil
+}
+
+func (l *pgLocker) Unlock(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+	defer func() {
+		_ = l.conn.Close()
+		l.conn = nil
+	}()
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key())
+	return err
+}
+
+// NewTableLocker 创建基于锁表实现的锁
+// 持有锁的实例异常退出时锁不会释放，超过 ttl 的锁视为过期，会被其他实例接管，ttl <= 0 时不过期
+func NewTableLocker(db *sqlx.DB, tableName string, timeout, ttl time.Duration) Locker {
+	return &tableLocker{db: db, tableName: tableName, timeout: timeout, ttl: ttl}
+}
+
+// tableLocker 基于锁表实现的锁，插入成功表示获得锁
+type tableLocker struct {
+	db        *sqlx.DB
+	tableName string
+	timeout   time.Duration
+	ttl       time.Duration
+}
+
+func (l *tableLocker) Lock(ctx context.Context) error {
+	createSQL := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INTEGER NOT NULL PRIMARY KEY, locked_at BIGINT NOT NULL)", l.tableName)
+	if _, err := l.db.ExecContext(ctx, createSQL); err != nil {
+		return err
+	}
+	insertSQL := l.db.Rebind(fmt.Sprintf("INSERT INTO %s (id, locked_at) VALUES (1, ?)", l.tableName))
+	expireSQL := l.db.Rebind(fmt.Sprintf("DELETE FROM %s WHERE id = 1 AND locked_at < ?", l.tableName))
+	return retry(ctx, l.timeout, func() (bool, error) {
+		now := time.Now()
+		if _, err := l.db.ExecContext(ctx, insertSQL, now.Unix()); err == nil {
+			return true, nil
+		}
+		// 插入失败说明锁已被其他实例持有，锁过期时删除后重新竞争
+		if l.ttl <= 0 {
+			return false, nil
+		}
+		result, err := l.db.ExecContext(ctx, expireSQL, now.Add(-l.ttl).Unix())
+		if err != nil {
+			return false, err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return false, nil
+		}
+		_, err = l.db.ExecContext(ctx, insertSQL, now.Unix())
+		return err == nil, nil
+	})
+}
+
+func (l *tableLocker) Unlock(ctx context.Context) error {
+	_, err := l.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = 1", l.tableName))
+	return err
+}
+
+// retry 重复尝试获取锁直到超时
+func retry(ctx context.Context, timeout time.Duration, fn func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := fn()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(ErrLocked, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
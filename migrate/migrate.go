@@ -0,0 +1,342 @@
+// Package migrate 数据库版本迁移
+// 按版本号顺序执行 up/down sql 文件或 go 函数，已执行的版本记录在 daox_migrations 表中
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultTableName 默认的迁移记录表
+const DefaultTableName = "daox_migrations"
+
+var (
+	ErrLocked           = errors.New("[migrate] can not acquire migration lock")
+	ErrDuplicateVersion = errors.New("[migrate] duplicate migration version")
+	ErrChecksumMismatch = errors.New("[migrate] applied migration has been modified")
+	ErrMissingMigration = errors.New("[migrate] applied migration not found in source")
+	ErrIrreversible     = errors.New("[migrate] migration has no down")
+)
+
+// Record 迁移执行记录
+type Record struct {
+	Version   int64  `db:"version"`
+	Name      string `db:"name"`
+	Checksum  string `db:"checksum"`
+	AppliedAt int64  `db:"applied_at"`
+}
+
+// Status 迁移版本状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified 已执行的迁移内容被修改
+	Modified bool
+	// Missing 已执行的版本在迁移源中不存在
+	Missing bool
+}
+
+type Options struct {
+	tableName   string
+	locker      Locker
+	lockTimeout time.Duration
+	lockTTL     time.Duration
+}
+
+type Option func(*Options)
+
+// WithTableName 设置迁移记录表名，默认 daox_migrations
+func WithTableName(tableName string) Option {
+	return func(o *Options) {
+		o.tableName = tableName
+	}
+}
+
+// WithLocker 设置迁移锁，默认根据数据库驱动选择
+func WithLocker(locker Locker) Option {
+	return func(o *Options) {
+		o.locker = locker
+	}
+}
+
+// WithLockTimeout 设置获取迁移锁的超时时间，默认 1 分钟
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.lockTimeout = timeout
+	}
+}
+
+// WithLockTTL 设置锁表的过期时间，默认 DefaultLockTTL，超过过期时间没有释放的锁会被接管
+// 只对使用锁表的数据库生效，需要大于迁移的执行时间
+func WithLockTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.lockTTL = ttl
+	}
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *sqlx.DB
+	tableName  string
+	locker     Locker
+	migrations []*Migration
+}
+
+// New 创建迁移执行器
+// migrations 可以通过 LoadDir、LoadFS 加载 sql 文件，也可以直接定义 go 函数实现的迁移
+func New(db *sqlx.DB, migrations []*Migration, opts ...Option) (*Migrator, error) {
+	options := &Options{
+		tableName:   DefaultTableName,
+		lockTimeout: time.Minute,
+		lockTTL:     DefaultLockTTL,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.locker == nil {
+		options.locker = newLocker(db, options.tableName, options.lockTimeout, options.lockTTL)
+	}
+	list := make([]*Migration, len(migrations))
+	copy(list, migrations)
+	sortMigrations(list)
+	for i := 1; i < len(list); i++ {
+		if list[i].Version == list[i-1].Version {
+			return nil, fmt.Errorf("%w: %s and %s", ErrDuplicateVersion, list[i-1], list[i])
+		}
+	}
+	return &Migrator{
+		db:         db,
+		tableName:  options.tableName,
+		locker:     options.locker,
+		migrations: list,
+	}, nil
+}
+
+// Migrations 返回所有迁移版本，按版本号升序
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Status 查询所有迁移版本的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statusMap := make(map[int64]*Status)
+	for _, mig := range m.migrations {
+		statusMap[mig.Version] = &Status{
+			Version: mig.Version,
+			Name:    mig.Name,
+		}
+	}
+	for version, record := range records {
+		status, ok := statusMap[version]
+		if !ok {
+			status = &Status{Version: version, Name: record.Name, Missing: true}
+			statusMap[version] = status
+		} else {
+			status.Modified = record.Checksum != m.find(version).Checksum()
+		}
+		status.Applied = true
+		status.AppliedAt = time.Unix(record.AppliedAt, 0)
+	}
+	list := make([]*Status, 0, len(statusMap))
+	for _, status := range statusMap {
+		list = append(list, status)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+// Up 按版本号升序执行未执行的迁移，n <= 0 时执行全部
+// 返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context, n int) ([]*Migration, error) {
+	var applied []*Migration
+	err := m.withLock(ctx, func(records map[int64]*Record) error {
+		for _, mig := range m.migrations {
+			if n > 0 && len(applied) >= n {
+				break
+			}
+			if _, ok := records[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, mig, true); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 按版本号降序回滚已执行的迁移，n <= 0 时回滚全部
+// 返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, n int) ([]*Migration, error) {
+	var reverted []*Migration
+	err := m.withLock(ctx, func(records map[int64]*Record) error {
+		for _, version := range appliedVersions(records) {
+			if n > 0 && len(reverted) >= n {
+				break
+			}
+			mig := m.find(version)
+			if err := m.apply(ctx, mig, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Redo 回滚最后一个已执行的迁移并重新执行
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redo *Migration
+	err := m.withLock(ctx, func(records map[int64]*Record) error {
+		versions := appliedVersions(records)
+		if len(versions) == 0 {
+			return nil
+		}
+		mig := m.find(versions[0])
+		if err := m.apply(ctx, mig, false); err != nil {
+			return err
+		}
+		if err := m.apply(ctx, mig, true); err != nil {
+			return err
+		}
+		redo = mig
+		return nil
+	})
+	return redo, err
+}
+
+// withLock 获取迁移锁，校验已执行的迁移后执行 fn
+func (m *Migrator) withLock(ctx context.Context, fn func(records map[int64]*Record) error) (err error) {
+	if err = m.ensureTable(ctx); err != nil {
+		return err
+	}
+	if err = m.locker.Lock(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := m.locker.Unlock(context.WithoutCancel(ctx)); err == nil {
+			err = unlockErr
+		}
+	}()
+	records, err := m.records(ctx)
+	if err != nil {
+		return err
+	}
+	for version, record := range records {
+		mig := m.find(version)
+		if mig == nil {
+			return fmt.Errorf("%w: %d_%s", ErrMissingMigration, version, record.Name)
+		}
+		if mig.Checksum() != record.Checksum {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, mig)
+		}
+	}
+	return fn(records)
+}
+
+// apply 在事务中执行迁移并更新迁移记录
+func (m *Migrator) apply(ctx context.Context, mig *Migration, up bool) (err error) {
+	fn, text := mig.Up, mig.UpSQL
+	if !up {
+		fn, text = mig.Down, mig.DownSQL
+		if fn == nil && len(SplitStatements(text)) == 0 {
+			return fmt.Errorf("%w: %s", ErrIrreversible, mig)
+		}
+	}
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			err = fmt.Errorf("[migrate] %s: %w", mig, err)
+			return
+		}
+		err = tx.Commit()
+	}()
+	if fn != nil {
+		err = fn(ctx, tx)
+	} else {
+		for _, stmt := range SplitStatements(text) {
+			if _, err = tx.ExecContext(ctx, stmt); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if up {
+		insertSQL := tx.Rebind(fmt.Sprintf("INSERT INTO %s (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)", m.tableName))
+		_, err = tx.ExecContext(ctx, insertSQL, mig.Version, mig.Name, mig.Checksum(), time.Now().Unix())
+		return err
+	}
+	deleteSQL := tx.Rebind(fmt.Sprintf("DELETE FROM %s WHERE version = ?", m.tableName))
+	_, err = tx.ExecContext(ctx, deleteSQL, mig.Version)
+	return err
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	createSQL := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
+		"version BIGINT NOT NULL PRIMARY KEY, "+
+		"name VARCHAR(255) NOT NULL, "+
+		"checksum VARCHAR(64) NOT NULL, "+
+		"applied_at BIGINT NOT NULL)", m.tableName)
+	_, err := m.db.ExecContext(ctx, createSQL)
+	return err
+}
+
+func (m *Migrator) records(ctx context.Context) (map[int64]*Record, error) {
+	var list []*Record
+	querySQL := fmt.Sprintf("SELECT version, name, checksum, applied_at FROM %s", m.tableName)
+	if err := m.db.SelectContext(ctx, &list, querySQL); err != nil {
+		return nil, err
+	}
+	records := make(map[int64]*Record, len(list))
+	for _, record := range list {
+		records[record.Version] = record
+	}
+	return records, nil
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	i := sort.Search(len(m.migrations), func(i int) bool {
+		return m.migrations[i].Version >= version
+	})
+	if i < len(m.migrations) && m.migrations[i].Version == version {
+		return m.migrations[i]
+	}
+	return nil
+}
+
+// appliedVersions 已执行的版本号，按降序排列
+func appliedVersions(records map[int64]*Record) []int64 {
+	versions := make([]int64, 0, len(records))
+	for version := range records {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+	return versions
+}
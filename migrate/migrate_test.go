@@ -0,0 +1,212 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox/migrate"
+)
+
+func newDB(t *testing.T) *sqlx.DB {
+	db := sqlx.MustOpen("sqlite3", filepath.Join(t.TempDir(), "migrate.db"))
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+func tableExists(t *testing.T, db *sqlx.DB, name string) bool {
+	var count int
+	err := db.Get(&count, "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name)
+	assert.NoError(t, err)
+	return count > 0
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	dir := writeFiles(t, map[string]string{
+		"1_create_user.up.sql":   "CREATE TABLE user (id integer primary key, name text);\n-- comment; with semicolon\nINSERT INTO user (name) VALUES ('a;b');",
+		"1_create_user.down.sql": "DROP TABLE user;",
+		"2_create_blog.up.sql":   "CREATE TABLE blog (id integer primary key, title text);",
+		"2_create_blog.down.sql": "DROP TABLE blog;",
+		"README.md":              "ignore",
+	})
+	migrations, err := migrate.LoadDir(dir)
+	assert.NoError(t, err)
+	migrations = append(migrations, &migrate.Migration{
+		Version: 3,
+		Name:    "add_user",
+		Up: func(ctx context.Context, tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO user (name) VALUES ('go')")
+			return err
+		},
+		Down: func(ctx context.Context, tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, "DELETE FROM user WHERE name = 'go'")
+			return err
+		},
+	})
+	m, err := migrate.New(db, migrations)
+	assert.NoError(t, err)
+
+	applied, err := m.Up(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.True(t, tableExists(t, db, "user"))
+	assert.False(t, tableExists(t, db, "blog"))
+	var name string
+	assert.NoError(t, db.Get(&name, "SELECT name FROM user"))
+	assert.Equal(t, "a;b", name)
+
+	applied, err = m.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+	var count int
+	assert.NoError(t, db.Get(&count, "SELECT count(*) FROM user"))
+	assert.Equal(t, 2, count)
+
+	list, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+	for _, status := range list {
+		assert.True(t, status.Applied)
+		assert.False(t, status.Modified)
+	}
+
+	redo, err := m.Redo(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), redo.Version)
+	assert.NoError(t, db.Get(&count, "SELECT count(*) FROM user"))
+	assert.Equal(t, 2, count)
+
+	reverted, err := m.Down(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, reverted, 2)
+	assert.Equal(t, int64(3), reverted[0].Version)
+	assert.Equal(t, int64(2), reverted[1].Version)
+	assert.False(t, tableExists(t, db, "blog"))
+	assert.True(t, tableExists(t, db, "user"))
+
+	list, err = m.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, list[0].Applied)
+	assert.False(t, list[1].Applied)
+	assert.False(t, list[2].Applied)
+
+	// 已执行的迁移被修改
+	migrations[0].UpSQL += "\n-- changed"
+	changed, err := migrate.New(db, migrations)
+	assert.NoError(t, err)
+	_, err = changed.Up(ctx, 0)
+	assert.True(t, errors.Is(err, migrate.ErrChecksumMismatch))
+	list, err = changed.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, list[0].Modified)
+}
+
+func TestMigratorFailed(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	m, err := migrate.New(db, []*migrate.Migration{
+		{Version: 1, Name: "ok", UpSQL: "CREATE TABLE t1 (id integer)"},
+		{Version: 2, Name: "bad", UpSQL: "CREATE TABLE t2 (id integer); INSERT INTO not_exist VALUES (1)"},
+	})
+	assert.NoError(t, err)
+	applied, err := m.Up(ctx, 0)
+	assert.Error(t, err)
+	assert.Len(t, applied, 1)
+	// 失败的迁移在事务中回滚
+	assert.False(t, tableExists(t, db, "t2"))
+
+	_, err = m.Down(ctx, 1)
+	assert.True(t, errors.Is(err, migrate.ErrIrreversible))
+
+	_, err = migrate.New(db, []*migrate.Migration{
+		{Version: 1, Name: "a"},
+		{Version: 1, Name: "b"},
+	})
+	assert.True(t, errors.Is(err, migrate.ErrDuplicateVersion))
+}
+
+func TestMigratorLock(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	locker := migrate.NewLocker(db, migrate.DefaultTableName, 200*time.Millisecond)
+	assert.NoError(t, locker.Lock(ctx))
+
+	m, err := migrate.New(db, []*migrate.Migration{
+		{Version: 1, Name: "t1", UpSQL: "CREATE TABLE t1 (id integer)"},
+	}, migrate.WithLockTimeout(200*time.Millisecond))
+	assert.NoError(t, err)
+	_, err = m.Up(ctx, 0)
+	assert.True(t, errors.Is(err, migrate.ErrLocked))
+
+	assert.NoError(t, locker.Unlock(ctx))
+	applied, err := m.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+}
+
+func TestMigratorStaleLock(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	locker := migrate.NewTableLocker(db, migrate.DefaultTableName+"_lock", 200*time.Millisecond, time.Minute)
+	assert.NoError(t, locker.Lock(ctx))
+	// 未过期的锁不能被接管
+	assert.True(t, errors.Is(locker.Lock(ctx), migrate.ErrLocked))
+
+	// 模拟持有锁的实例异常退出
+	_, err := db.Exec("UPDATE daox_migrations_lock SET locked_at = ? WHERE id = 1", time.Now().Add(-time.Hour).Unix())
+	assert.NoError(t, err)
+	m, err := migrate.New(db, []*migrate.Migration{
+		{Version: 1, Name: "t1", UpSQL: "CREATE TABLE t1 (id integer)"},
+	}, migrate.WithLockTimeout(200*time.Millisecond), migrate.WithLockTTL(0))
+	assert.NoError(t, err)
+	_, err = m.Up(ctx, 0)
+	assert.True(t, errors.Is(err, migrate.ErrLocked))
+
+	m, err = migrate.New(db, []*migrate.Migration{
+		{Version: 1, Name: "t1", UpSQL: "CREATE TABLE t1 (id integer)"},
+	}, migrate.WithLockTimeout(200*time.Millisecond), migrate.WithLockTTL(time.Minute))
+	assert.NoError(t, err)
+	applied, err := m.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.False(t, lockHeld(t, db))
+}
+
+func lockHeld(t *testing.T, db *sqlx.DB) bool {
+	var count int
+	assert.NoError(t, db.Get(&count, "SELECT count(*) FROM daox_migrations_lock"))
+	return count > 0
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := migrate.SplitStatements(`
+-- create table
+CREATE TABLE a (name varchar(10) DEFAULT ';');
+/* multi ; line */
+INSERT INTO a VALUES ('it''s; ok'), ("x;y");
+;
+`)
+	assert.Equal(t, []string{
+		"CREATE TABLE a (name varchar(10) DEFAULT ';')",
+		"INSERT INTO a VALUES ('it''s; ok'), (\"x;y\")",
+	}, stmts)
+}
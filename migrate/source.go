@@ -0,0 +1,174 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Func go 代码实现的迁移函数，在事务中执行
+type Func func(ctx context.Context, tx *sqlx.Tx) error
+
+// Migration 迁移版本
+type Migration struct {
+	Version int64
+	Name    string
+	// UpSQL 升级 sql，可以包含多条语句
+	UpSQL string
+	// DownSQL 回滚 sql，可以包含多条语句
+	DownSQL string
+	// Up go 代码实现的升级函数，设置后忽略 UpSQL
+	Up Func
+	// Down go 代码实现的回滚函数，设置后忽略 DownSQL
+	Down Func
+}
+
+// Checksum 迁移内容的校验和，用于检查已执行的迁移是否被修改
+// go 代码实现的迁移只校验版本号和名称
+func (m *Migration) Checksum() string {
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatInt(m.Version, 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(m.Name))
+	if m.Up == nil {
+		h.Write([]byte{0})
+		h.Write([]byte(m.UpSQL))
+	}
+	if m.Down == nil {
+		h.Write([]byte{0})
+		h.Write([]byte(m.DownSQL))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// fileNameRegexp 迁移文件名格式：{version}_{name}.up.sql / {version}_{name}.down.sql
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// LoadDir 从目录中加载 sql 迁移文件
+func LoadDir(dir string) ([]*Migration, error) {
+	return LoadFS(os.DirFS(dir), ".")
+}
+
+// LoadFS 从文件系统中加载 sql 迁移文件，可以配合 embed 使用
+// 文件名格式：{version}_{name}.up.sql / {version}_{name}.down.sql
+// eg: 20240101120000_create_user.up.sql
+func LoadFS(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	migrationMap := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("[migrate] invalid version in file %s: %w", entry.Name(), err)
+		}
+		bs, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := migrationMap[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			migrationMap[version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("%w: %d_%s and %d_%s", ErrDuplicateVersion, version, m.Name, version, matches[2])
+		}
+		if matches[3] == "up" {
+			m.UpSQL = string(bs)
+		} else {
+			m.DownSQL = string(bs)
+		}
+	}
+	migrations := make([]*Migration, 0, len(migrationMap))
+	for _, m := range migrationMap {
+		migrations = append(migrations, m)
+	}
+	sortMigrations(migrations)
+	return migrations, nil
+}
+
+func sortMigrations(migrations []*Migration) {
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+}
+
+// SplitStatements 将 sql 文本按 `;` 拆分成多条语句
+// 会忽略引号和注释中的 `;`
+func SplitStatements(text string) []string {
+	var (
+		stmts []string
+		buf   strings.Builder
+		quote rune
+	)
+	runes := []rune(text)
+	flush := func() {
+		stmt := strings.TrimSpace(buf.String())
+		buf.Reset()
+		if stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if quote != 0 {
+			buf.WriteRune(c)
+			if c == '\\' && quote != '`' && i+1 < len(runes) {
+				i++
+				buf.WriteRune(runes[i])
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			buf.WriteRune(c)
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// 单行注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			buf.WriteRune('\n')
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// 多行注释
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				i++
+			}
+			i++
+			buf.WriteRune(' ')
+		case c == ';':
+			flush()
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	flush()
+	return stmts
+}
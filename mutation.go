@@ -2,9 +2,13 @@ package daox
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/fengjx/daox/engine"
 	"github.com/fengjx/daox/sqlbuilder"
+	"github.com/fengjx/daox/utils"
 )
 
 // InsertRecord 插入记录
@@ -20,11 +24,7 @@ func Insert(ctx context.Context, execer engine.Execer, record InsertRecord, opts
 		option(opt)
 	}
 	inserter := sqlbuilder.NewInserter(record.TableName)
-	var columns []string
-	for col := range record.Row {
-		columns = append(columns, col)
-	}
-	inserter.Columns(columns...)
+	inserter.Columns(sortedKeys(record.Row)...)
 	sql, err := inserter.NameSQL()
 	if err != nil {
 		return 0, err
@@ -46,10 +46,14 @@ type UpdateRecord struct {
 // Update 通用 update 操作
 func Update(ctx context.Context, execer engine.Execer, record UpdateRecord) (int64, error) {
 	updater := sqlbuilder.NewUpdater(record.TableName)
-	for col, val := range record.Row {
-		updater.Set(col, val)
+	for _, col := range sortedKeys(record.Row) {
+		updater.Set(col, record.Row[col])
 	}
-	updater.Where(buildCondition(record.Conditions))
+	where, err := buildCondition(record.Conditions)
+	if err != nil {
+		return 0, err
+	}
+	updater.Where(where)
 	sql, args, err := updater.SQLArgs()
 	if err != nil {
 		return 0, err
@@ -69,8 +73,12 @@ type DeleteRecord struct {
 
 // Delete 通用 delete 操作
 func Delete(ctx context.Context, execer engine.Execer, record DeleteRecord) (int64, error) {
+	where, err := buildCondition(record.Conditions)
+	if err != nil {
+		return 0, err
+	}
 	deleter := sqlbuilder.NewDeleter(record.TableName)
-	deleter.Where(buildCondition(record.Conditions))
+	deleter.Where(where)
 	sql, args, err := deleter.SQLArgs()
 	if err != nil {
 		return 0, err
@@ -81,3 +89,306 @@ func Delete(ctx context.Context, execer engine.Execer, record DeleteRecord) (int
 	}
 	return result.RowsAffected()
 }
+
+// ConflictPolicy 唯一键冲突时的处理方式
+type ConflictPolicy string
+
+const (
+	ConflictError   ConflictPolicy = "error"   // 返回错误
+	ConflictIgnore  ConflictPolicy = "ignore"  // 忽略冲突的记录
+	ConflictReplace ConflictPolicy = "replace" // 删除旧记录后插入，使用 replace into
+	ConflictUpsert  ConflictPolicy = "upsert"  // 更新冲突的记录
+)
+
+// maxPlaceholders 单条语句最多的参数个数，mysql 限制为 65535，sqlite 默认限制为 32766
+func maxPlaceholders(dialect Dialect) int {
+	if dialect == DialectSQLite {
+		return 32766
+	}
+	return 65535
+}
+
+// ErrConflictPolicy 不支持的冲突处理方式
+var ErrConflictPolicy = errors.New("[daox] unsupported conflict policy")
+
+// BatchInsertRecord 批量插入记录
+type BatchInsertRecord struct {
+	TableName string           `json:"table_name"` // 表名
+	Rows      []map[string]any `json:"rows"`       // 行数据
+}
+
+// BatchInsert 通用批量 insert 操作，返回影响行数
+// 字段相同的行按字段名排序后合并插入，字段不同的行分成不同的语句，行中没有的字段使用表的默认值
+// 超过 batchSize 的数据会拆分成多条语句执行，需要原子性时可以传入事务
+// 无法识别驱动方言时不支持设置冲突处理方式，返回 ErrUnsupportedDialect
+func BatchInsert(ctx context.Context, execer engine.Execer, record BatchInsertRecord, opts ...BatchInsertOption) (int64, error) {
+	opt := &BatchInsertOptions{batchSize: 500, conflict: ConflictError}
+	for _, option := range opts {
+		option(opt)
+	}
+	if len(record.Rows) == 0 {
+		return 0, nil
+	}
+	dialect, ok := lookupDialect(execer)
+	if !ok && opt.conflict != ConflictError && opt.conflict != "" {
+		return 0, fmt.Errorf("%w: conflict policy %s", ErrUnsupportedDialect, opt.conflict)
+	}
+	var affected int64
+	for _, group := range groupRowsByColumns(record.Rows) {
+		if len(group.columns) == 0 {
+			return affected, sqlbuilder.ErrColumnsRequire
+		}
+		batchSize := opt.batchSize
+		if limit := maxPlaceholders(dialect); batchSize <= 0 || batchSize*len(group.columns) > limit {
+			batchSize = limit / len(group.columns)
+		}
+		for start := 0; start < len(group.rows); start += batchSize {
+			rows := group.rows[start:min(start+batchSize, len(group.rows))]
+			execSQL, err := batchInsertSQL(record.TableName, group.columns, len(rows), dialect, opt)
+			if err != nil {
+				return affected, err
+			}
+			args := make([]any, 0, len(rows)*len(group.columns))
+			for _, row := range rows {
+				for _, col := range group.columns {
+					args = append(args, row[col])
+				}
+			}
+			result, err := execer.ExecContext(ctx, execSQL, args...)
+			if err != nil {
+				return affected, err
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return affected, err
+			}
+			affected += n
+		}
+	}
+	return affected, nil
+}
+
+// rowGroup 字段相同的行
+type rowGroup struct {
+	columns []string         // 排序后的字段
+	rows    []map[string]any // 行数据
+	indexes []int            // 行在原数据中的下标
+}
+
+// groupRowsByColumns 按字段集合对行分组，分组顺序和组内行顺序与原数据一致
+func groupRowsByColumns(rows []map[string]any) []*rowGroup {
+	var groups []*rowGroup
+	groupMap := make(map[string]*rowGroup)
+	for i, row := range rows {
+		columns := sortedKeys(row)
+		key := strings.Join(columns, "\x00")
+		group, ok := groupMap[key]
+		if !ok {
+			group = &rowGroup{columns: columns}
+			groupMap[key] = group
+			groups = append(groups, group)
+		}
+		group.rows = append(group.rows, row)
+		group.indexes = append(group.indexes, i)
+	}
+	return groups
+}
+
+// batchInsertSQL 生成多行 insert 语句
+func batchInsertSQL(tableName string, columns []string, rows int, dialect Dialect, opt *BatchInsertOptions) (string, error) {
+	var sb strings.Builder
+	switch opt.conflict {
+	case ConflictError, ConflictUpsert, "":
+		sb.WriteString("INSERT INTO ")
+	case ConflictIgnore:
+		if dialect == DialectSQLite {
+			sb.WriteString("INSERT OR IGNORE INTO ")
+		} else {
+			sb.WriteString("INSERT IGNORE INTO ")
+		}
+	case ConflictReplace:
+		sb.WriteString("REPLACE INTO ")
+	default:
+		return "", fmt.Errorf("%w: %s", ErrConflictPolicy, opt.conflict)
+	}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = sqlbuilder.Quote(col)
+	}
+	sb.WriteString(sqlbuilder.Quote(tableName))
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(quoted, ", "))
+	sb.WriteString(") VALUES ")
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(placeholders)
+	}
+	if opt.conflict == ConflictUpsert {
+		upsert, err := upsertClause(tableName, columns, dialect, opt)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(upsert)
+	}
+	sb.WriteByte(';')
+	return sb.String(), nil
+}
+
+// upsertClause 生成冲突时更新的语句
+// mysql: ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)
+// sqlite: ON CONFLICT (`id`) DO UPDATE SET `name` = excluded.`name`
+func upsertClause(tableName string, columns []string, dialect Dialect, opt *BatchInsertOptions) (string, error) {
+	conflictColumns := opt.conflictColumns
+	if len(conflictColumns) == 0 {
+		if meta, ok := GetMetaInfo(tableName); ok && meta.PrimaryKey != "" {
+			conflictColumns = []string{meta.PrimaryKey}
+		}
+	}
+	updateColumns := opt.updateColumns
+	if len(updateColumns) == 0 {
+		for _, col := range columns {
+			if !utils.ContainsString(conflictColumns, col) {
+				updateColumns = append(updateColumns, col)
+			}
+		}
+	}
+	if len(updateColumns) == 0 {
+		return "", fmt.Errorf("%w: upsert without update columns", ErrConflictPolicy)
+	}
+	sets := make([]string, len(updateColumns))
+	for i, col := range updateColumns {
+		if dialect == DialectSQLite {
+			sets[i] = sqlbuilder.Quote(col) + " = excluded." + sqlbuilder.Quote(col)
+		} else {
+			sets[i] = sqlbuilder.Quote(col) + " = VALUES(" + sqlbuilder.Quote(col) + ")"
+		}
+	}
+	if dialect != DialectSQLite {
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), nil
+	}
+	if len(conflictColumns) == 0 {
+		return "", fmt.Errorf("%w: sqlite upsert requires conflict columns", ErrConflictPolicy)
+	}
+	quoted := make([]string, len(conflictColumns))
+	for i, col := range conflictColumns {
+		quoted[i] = sqlbuilder.Quote(col)
+	}
+	return " ON CONFLICT (" + strings.Join(quoted, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", "), nil
+}
+
+// dialectOfExecer 根据 execer 的驱动名获得方言，无法识别时使用 mysql
+func dialectOfExecer(execer engine.Execer) Dialect {
+	dialect, _ := lookupDialect(execer)
+	return dialect
+}
+
+// lookupDialect 根据 execer 的驱动名获得方言，无法识别时返回 mysql 和 false
+func lookupDialect(execer engine.Execer) (Dialect, bool) {
+	if d, ok := execer.(interface{ DriverName() string }); ok {
+		if dialect, err := DialectOf(d.DriverName()); err == nil {
+			return dialect, true
+		}
+	}
+	return DialectMySQL, false
+}
+
+// ErrInvalidMutation Mutation 没有设置或者设置了多个操作
+var ErrInvalidMutation = errors.New("[daox] mutation must have exactly one operation")
+
+// Mutation 写操作，只能设置其中一个
+type Mutation struct {
+	Insert      *InsertRecord      `json:"insert,omitempty"`
+	BatchInsert *BatchInsertRecord `json:"batch_insert,omitempty"`
+	Update      *UpdateRecord      `json:"update,omitempty"`
+	Delete      *DeleteRecord      `json:"delete,omitempty"`
+}
+
+// MutationResult 写操作结果
+type MutationResult struct {
+	LastInsertID int64 `json:"last_insert_id"` // Insert 返回的自增 id
+	Affected     int64 `json:"affected"`       // 影响行数
+}
+
+// MutationBatch 批量写操作，在同一个事务中按顺序执行
+type MutationBatch struct {
+	Mutations []Mutation `json:"mutations"`
+}
+
+// Insert 添加 insert 操作
+func (b *MutationBatch) Insert(record InsertRecord) *MutationBatch {
+	b.Mutations = append(b.Mutations, Mutation{Insert: &record})
+	return b
+}
+
+// BatchInsert 添加批量 insert 操作
+func (b *MutationBatch) BatchInsert(record BatchInsertRecord) *MutationBatch {
+	b.Mutations = append(b.Mutations, Mutation{BatchInsert: &record})
+	return b
+}
+
+// Update 添加 update 操作
+func (b *MutationBatch) Update(record UpdateRecord) *MutationBatch {
+	b.Mutations = append(b.Mutations, Mutation{Update: &record})
+	return b
+}
+
+// Delete 添加 delete 操作
+func (b *MutationBatch) Delete(record DeleteRecord) *MutationBatch {
+	b.Mutations = append(b.Mutations, Mutation{Delete: &record})
+	return b
+}
+
+// Exec 通过事务管理器执行所有写操作，任意一个操作失败时全部回滚
+// 返回结果和 Mutations 一一对应
+func (b *MutationBatch) Exec(ctx context.Context, txManager *TxManager, opts ...BatchInsertOption) ([]MutationResult, error) {
+	for i, m := range b.Mutations {
+		if m.count() != 1 {
+			return nil, fmt.Errorf("%w: index %d", ErrInvalidMutation, i)
+		}
+	}
+	results := make([]MutationResult, len(b.Mutations))
+	err := txManager.ExecTx(ctx, func(txCtx context.Context, executor engine.Executor) error {
+		for i, m := range b.Mutations {
+			result, err := m.exec(txCtx, executor, opts...)
+			if err != nil {
+				return fmt.Errorf("[daox] mutation %d: %w", i, err)
+			}
+			results[i] = result
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (m Mutation) count() int {
+	n := 0
+	for _, set := range []bool{m.Insert != nil, m.BatchInsert != nil, m.Update != nil, m.Delete != nil} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+func (m Mutation) exec(ctx context.Context, execer engine.Execer, opts ...BatchInsertOption) (result MutationResult, err error) {
+	switch {
+	case m.Insert != nil:
+		result.LastInsertID, err = Insert(ctx, execer, *m.Insert)
+		if err == nil {
+			result.Affected = 1
+		}
+	case m.BatchInsert != nil:
+		result.Affected, err = BatchInsert(ctx, execer, *m.BatchInsert, opts...)
+	case m.Update != nil:
+		result.Affected, err = Update(ctx, execer, *m.Update)
+	case m.Delete != nil:
+		result.Affected, err = Delete(ctx, execer, *m.Delete)
+	}
+	return
+}
@@ -2,9 +2,13 @@ package daox_test
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/fengjx/daox"
@@ -85,3 +89,196 @@ func TestDelete(t *testing.T) {
 	t.Log("update affected:", affected)
 	assert.Equal(t, true, affected > 0)
 }
+
+func TestInsertColumnOrder(t *testing.T) {
+	dbx, mock, err := newMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbx.Close()
+	for i := 0; i < 3; i++ {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `user`(`age`, `name`, `sex`) VALUES (?, ?, ?);")).
+			WithArgs(18, "u1", "male").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	for i := 0; i < 3; i++ {
+		_, err = daox.Insert(context.Background(), dbx, daox.InsertRecord{
+			TableName: "user",
+			Row:       map[string]any{"sex": "male", "name": "u1", "age": 18},
+		})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchInsert(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_batch_insert"
+	before(t, tableName)
+	db := newDb()
+	rows := make([]map[string]any, 0, 7)
+	for i := 0; i < 7; i++ {
+		row := map[string]any{"uid": 1000 + i, "name": fmt.Sprintf("batch-%d", i)}
+		if i%2 == 0 {
+			row["sex"] = "female"
+		}
+		rows = append(rows, row)
+	}
+	affected, err := daox.BatchInsert(ctx, db, daox.BatchInsertRecord{TableName: tableName, Rows: rows}, daox.WithBatchSize(3))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), affected)
+	var count int64
+	assert.NoError(t, db.Get(&count, fmt.Sprintf("SELECT count(*) FROM %s WHERE sex IS NULL AND uid >= 1000", tableName)))
+	assert.Equal(t, int64(3), count)
+
+	// 主键冲突
+	conflictRows := []map[string]any{
+		{"id": 1, "name": "upsert-1", "uid": 1},
+		{"id": 100, "name": "upsert-100", "uid": 100},
+	}
+	_, err = daox.BatchInsert(ctx, db, daox.BatchInsertRecord{TableName: tableName, Rows: conflictRows})
+	assert.Error(t, err)
+
+	affected, err = daox.BatchInsert(ctx, db, daox.BatchInsertRecord{TableName: tableName, Rows: conflictRows},
+		daox.WithConflictPolicy(daox.ConflictIgnore))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	_, err = daox.BatchInsert(ctx, db, daox.BatchInsertRecord{TableName: tableName, Rows: conflictRows},
+		daox.WithUpsert(nil, "name"))
+	assert.NoError(t, err)
+	var info DemoInfo
+	assert.NoError(t, db.Get(&info, fmt.Sprintf("SELECT * FROM %s WHERE id = 1", tableName)))
+	assert.Equal(t, "upsert-1", info.Name)
+	assert.Equal(t, int64(100), info.UID)
+
+	_, err = daox.BatchInsert(ctx, db, daox.BatchInsertRecord{TableName: tableName, Rows: conflictRows},
+		daox.WithConflictPolicy(daox.ConflictReplace))
+	assert.NoError(t, err)
+	assert.NoError(t, db.Get(&info, fmt.Sprintf("SELECT id, uid, name FROM %s WHERE id = 1", tableName)))
+	assert.Equal(t, int64(1), info.UID)
+
+	_, err = daox.BatchInsert(ctx, db, daox.BatchInsertRecord{TableName: tableName, Rows: conflictRows},
+		daox.WithConflictPolicy("merge"))
+	assert.ErrorIs(t, err, daox.ErrConflictPolicy)
+
+	// 超过 sqlite 参数个数限制时拆分成多条语句
+	wideRows := make([]map[string]any, 0, 12000)
+	for i := 0; i < 12000; i++ {
+		wideRows = append(wideRows, map[string]any{"uid": 10000 + i, "name": "wide", "sex": "male"})
+	}
+	affected, err = daox.BatchInsert(ctx, db, daox.BatchInsertRecord{TableName: tableName, Rows: wideRows},
+		daox.WithBatchSize(20000))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12000), affected)
+}
+
+func TestBatchInsertMySQL(t *testing.T) {
+	dbx, mock, err := newMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbx.Close()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `user` (`id`, `name`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`);")).
+		WithArgs(1, "u1", 3, "u3").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `user` (`age`, `id`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `age` = VALUES(`age`);")).
+		WithArgs(20, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT IGNORE INTO `user` (`id`, `name`) VALUES (?, ?);")).
+		WithArgs(1, "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	affected, err := daox.BatchInsert(context.Background(), dbx, daox.BatchInsertRecord{
+		TableName: "user",
+		Rows:      []map[string]any{{"id": 1, "name": "u1"}, {"id": 2, "age": 20}, {"id": 3, "name": "u3"}},
+	}, daox.WithUpsert([]string{"id"}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+	_, err = daox.BatchInsert(context.Background(), dbx, daox.BatchInsertRecord{
+		TableName: "user",
+		Rows:      []map[string]any{{"id": 1, "name": "u1"}},
+	}, daox.WithConflictPolicy(daox.ConflictIgnore))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchInsertDefault(t *testing.T) {
+	db := newDb()
+	_, err := db.Exec("DROP TABLE IF EXISTS batch_default")
+	assert.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE batch_default (id integer primary key autoincrement, name text, status integer NOT NULL DEFAULT 1)")
+	assert.NoError(t, err)
+	defer db.Exec("DROP TABLE IF EXISTS batch_default")
+	// 没有 status 的行使用默认值
+	affected, err := daox.BatchInsert(context.Background(), db, daox.BatchInsertRecord{
+		TableName: "batch_default",
+		Rows:      []map[string]any{{"name": "d1"}, {"name": "d2", "status": 2}, {"name": "d3"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+	var status []int
+	assert.NoError(t, db.Select(&status, "SELECT status FROM batch_default ORDER BY name"))
+	assert.Equal(t, []int{1, 2, 1}, status)
+}
+
+func TestBatchInsertUnknownDialect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbx := sqlx.NewDb(db, "postgres")
+	defer dbx.Close()
+	record := daox.BatchInsertRecord{TableName: "user", Rows: []map[string]any{{"id": 1, "name": "u1"}}}
+	for _, opt := range []daox.BatchInsertOption{
+		daox.WithConflictPolicy(daox.ConflictIgnore),
+		daox.WithConflictPolicy(daox.ConflictReplace),
+		daox.WithUpsert([]string{"id"}),
+	} {
+		_, err = daox.BatchInsert(context.Background(), dbx, record, opt)
+		assert.ErrorIs(t, err, daox.ErrUnsupportedDialect)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMutationBatch(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_mutation_batch"
+	before(t, tableName)
+	db := newDb()
+	txManager := daox.NewTxManager(db)
+
+	batch := &daox.MutationBatch{}
+	batch.Insert(daox.InsertRecord{TableName: tableName, Row: map[string]any{"uid": 2000, "name": "m-1"}}).
+		BatchInsert(daox.BatchInsertRecord{TableName: tableName, Rows: []map[string]any{{"uid": 2001}, {"uid": 2002}}}).
+		Update(daox.UpdateRecord{
+			TableName:  tableName,
+			Row:        map[string]any{"name": "m-2"},
+			Conditions: []daox.Condition{{ConditionType: daox.ConditionTypeGt, Field: "uid", Vals: []any{2000}}},
+		}).
+		Delete(daox.DeleteRecord{
+			TableName:  tableName,
+			Conditions: []daox.Condition{{ConditionType: daox.ConditionTypeLt, Field: "uid", Vals: []any{105}}},
+		})
+	results, err := batch.Exec(ctx, txManager)
+	assert.NoError(t, err)
+	assert.Equal(t, []daox.MutationResult{
+		{LastInsertID: 11, Affected: 1},
+		{Affected: 2},
+		{Affected: 2},
+		{Affected: 5},
+	}, results)
+
+	// 任意一个操作失败全部回滚
+	batch = &daox.MutationBatch{}
+	batch.Insert(daox.InsertRecord{TableName: tableName, Row: map[string]any{"uid": 3000}}).
+		Update(daox.UpdateRecord{TableName: tableName, Row: map[string]any{"not_exist": 1}})
+	_, err = batch.Exec(ctx, txManager)
+	assert.Error(t, err)
+	var count int64
+	assert.NoError(t, db.Get(&count, fmt.Sprintf("SELECT count(*) FROM %s WHERE uid = 3000", tableName)))
+	assert.Equal(t, int64(0), count)
+
+	batch = &daox.MutationBatch{Mutations: []daox.Mutation{{}}}
+	_, err = batch.Exec(ctx, txManager)
+	assert.ErrorIs(t, err, daox.ErrInvalidMutation)
+}
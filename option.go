@@ -1,6 +1,8 @@
 package daox
 
 import (
+	"strings"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/reflectx"
 
@@ -17,6 +19,8 @@ type Options struct {
 	ifNullVals    map[string]string
 	hooks         []engine.Hook
 	printSQL      engine.AfterHandler
+	tenantColumn  string
+	auditSink     AuditSink
 }
 
 type Option func(*Options)
@@ -99,6 +103,22 @@ func WithPrintSQL(printSQL engine.AfterHandler) Option {
 	}
 }
 
+// WithTenantColumn 开启多租户数据隔离，col 为租户字段
+// 开启后查询、更新、删除会自动追加租户条件，保存时自动设置租户字段的值，租户 id 通过 WithTenant 设置到上下文中
+func WithTenantColumn(col string) Option {
+	return func(d *Options) {
+		d.tenantColumn = col
+	}
+}
+
+// WithAudit 开启审计，更新、删除时在同一个事务中加载变更前后的数据，生成 AuditRecord 写入 sink
+// 操作人通过 WithActor 设置到上下文中，可以使用 NewTableAuditSink 写入审计表
+func WithAudit(sink AuditSink) Option {
+	return func(d *Options) {
+		d.auditSink = sink
+	}
+}
+
 // InsertOptions insert 选项
 type InsertOptions struct {
 	disableGlobalOmitColumns bool     // 禁用全局忽略字段
@@ -120,3 +140,200 @@ func WithInsertOmits(omits ...string) InsertOption {
 		o.omitColumns = append(o.omitColumns, omits...)
 	}
 }
+
+// FilterOptions 查询参数解析选项
+type FilterOptions struct {
+	defaultLimit int64 // 没有指定 limit 时的每页记录数
+	maxLimit     int64 // limit 最大值，小于等于 0 表示不限制
+}
+
+type FilterOption func(*FilterOptions)
+
+// WithDefaultLimit 没有指定 limit 时的每页记录数，默认 20
+func WithDefaultLimit(limit int64) FilterOption {
+	return func(o *FilterOptions) {
+		o.defaultLimit = limit
+	}
+}
+
+// WithMaxLimit limit 最大值，超过时使用最大值，默认 1000，小于等于 0 表示不限制
+func WithMaxLimit(limit int64) FilterOption {
+	return func(o *FilterOptions) {
+		o.maxLimit = limit
+	}
+}
+
+// BatchInsertOptions 批量 insert 选项
+type BatchInsertOptions struct {
+	batchSize       int            // 每条语句最多插入的行数
+	conflict        ConflictPolicy // 唯一键冲突时的处理方式
+	conflictColumns []string       // upsert 的冲突字段，sqlite 需要指定，默认主键
+	updateColumns   []string       // upsert 时更新的字段，默认除冲突字段外的所有字段
+}
+
+type BatchInsertOption func(*BatchInsertOptions)
+
+// WithBatchSize 每条 insert 语句最多插入的行数，默认 500
+func WithBatchSize(size int) BatchInsertOption {
+	return func(o *BatchInsertOptions) {
+		o.batchSize = size
+	}
+}
+
+// WithConflictPolicy 唯一键冲突时的处理方式，默认返回错误
+func WithConflictPolicy(policy ConflictPolicy) BatchInsertOption {
+	return func(o *BatchInsertOptions) {
+		o.conflict = policy
+	}
+}
+
+// WithUpsert 唯一键冲突时更新记录
+// conflictColumns 冲突字段，只有 sqlite 需要，为空时使用表主键；updateColumns 为空时更新除冲突字段外的所有字段
+func WithUpsert(conflictColumns []string, updateColumns ...string) BatchInsertOption {
+	return func(o *BatchInsertOptions) {
+		o.conflict = ConflictUpsert
+		o.conflictColumns = conflictColumns
+		o.updateColumns = updateColumns
+	}
+}
+
+// ScanOptions ScanMap 选项
+type ScanOptions struct {
+	columnConverters map[string]ColumnConverter // 字段名对应的转换函数
+	typeConverters   map[string]ColumnConverter // 数据库类型对应的转换函数，类型为大写
+}
+
+type ScanOption func(*ScanOptions)
+
+// WithColumnConverter 设置字段值转换函数，优先于 WithTypeConverter
+func WithColumnConverter(column string, converter ColumnConverter) ScanOption {
+	return func(o *ScanOptions) {
+		if o.columnConverters == nil {
+			o.columnConverters = make(map[string]ColumnConverter)
+		}
+		o.columnConverters[column] = converter
+	}
+}
+
+// WithTypeConverter 根据数据库类型设置转换函数，eg: WithTypeConverter("DECIMAL", daox.StringConverter)
+func WithTypeConverter(dbType string, converter ColumnConverter) ScanOption {
+	return func(o *ScanOptions) {
+		if o.typeConverters == nil {
+			o.typeConverters = make(map[string]ColumnConverter)
+		}
+		o.typeConverters[strings.ToUpper(dbType)] = converter
+	}
+}
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	format      ExportFormat
+	header      bool
+	bom         bool
+	timeLayout  string
+	columnNames map[string]string
+	scanOpts    []ScanOption
+}
+
+type ExportOption func(*ExportOptions)
+
+// WithExportFormat 导出格式，默认 csv
+func WithExportFormat(format ExportFormat) ExportOption {
+	return func(o *ExportOptions) {
+		o.format = format
+	}
+}
+
+// WithExportHeader csv、tsv 是否输出表头，默认输出
+func WithExportHeader(header bool) ExportOption {
+	return func(o *ExportOptions) {
+		o.header = header
+	}
+}
+
+// WithExportBOM csv、tsv 输出 utf-8 BOM，excel 打开时中文不会乱码
+func WithExportBOM() ExportOption {
+	return func(o *ExportOptions) {
+		o.bom = true
+	}
+}
+
+// WithExportTimeLayout csv、tsv 时间字段的格式，默认 time.DateTime
+func WithExportTimeLayout(layout string) ExportOption {
+	return func(o *ExportOptions) {
+		o.timeLayout = layout
+	}
+}
+
+// WithExportColumnName 重命名导出的字段，csv、tsv 修改表头，jsonl 修改 key
+func WithExportColumnName(column, name string) ExportOption {
+	return func(o *ExportOptions) {
+		if o.columnNames == nil {
+			o.columnNames = make(map[string]string)
+		}
+		o.columnNames[column] = name
+	}
+}
+
+// WithExportFormatter 设置字段值格式化函数，NULL 值不会调用格式化函数
+func WithExportFormatter(column string, formatter ColumnConverter) ExportOption {
+	return func(o *ExportOptions) {
+		o.scanOpts = append(o.scanOpts, WithColumnConverter(column, formatter))
+	}
+}
+
+// WithExportScanOptions 设置扫描选项，参考 ScanMap
+func WithExportScanOptions(opts ...ScanOption) ExportOption {
+	return func(o *ExportOptions) {
+		o.scanOpts = append(o.scanOpts, opts...)
+	}
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	format      ExportFormat
+	columnNames map[string]string
+	batchOpts   []BatchInsertOption
+	batchSize   int
+	tx          bool
+}
+
+type ImportOption func(*ImportOptions)
+
+// WithImportFormat 导入文件格式，默认 csv
+func WithImportFormat(format ExportFormat) ImportOption {
+	return func(o *ImportOptions) {
+		o.format = format
+	}
+}
+
+// WithImportColumnName 表头或者 json key 与表字段不一致时设置映射关系
+func WithImportColumnName(name, column string) ImportOption {
+	return func(o *ImportOptions) {
+		if o.columnNames == nil {
+			o.columnNames = make(map[string]string)
+		}
+		o.columnNames[name] = column
+	}
+}
+
+// WithImportBatchSize 每次插入的行数，默认 500，超过数据库参数个数限制时会自动调小
+func WithImportBatchSize(size int) ImportOption {
+	return func(o *ImportOptions) {
+		o.batchSize = size
+	}
+}
+
+// WithImportBatchOptions 设置批量 insert 选项，eg: daox.WithConflictPolicy(daox.ConflictIgnore)
+func WithImportBatchOptions(opts ...BatchInsertOption) ImportOption {
+	return func(o *ImportOptions) {
+		o.batchOpts = append(o.batchOpts, opts...)
+	}
+}
+
+// WithImportTx 在同一个事务中导入，有任意一行失败时全部回滚
+func WithImportTx() ImportOption {
+	return func(o *ImportOptions) {
+		o.tx = true
+	}
+}
@@ -0,0 +1,176 @@
+package daox
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fengjx/daox/utils"
+)
+
+var (
+	// ErrTableNotRegistered 表没有注册元信息，通过 NewDao 或 NewDaoByMeta 创建 dao 时会自动注册
+	ErrTableNotRegistered = errors.New("[daox] table not registered")
+	// ErrUnknownField 字段不是表中的字段
+	ErrUnknownField = errors.New("[daox] unknown field")
+	// ErrFieldNotAllowed 字段不在表访问策略允许的范围内
+	ErrFieldNotAllowed = errors.New("[daox] field not allowed")
+)
+
+// FieldUsage 字段用途
+type FieldUsage string
+
+const (
+	FieldUsageQuery  FieldUsage = "query"  // 投影字段
+	FieldUsageSort   FieldUsage = "sort"   // 排序字段
+	FieldUsageFilter FieldUsage = "filter" // 条件字段
+)
+
+// FieldError 字段校验错误，可以通过 errors.Is 判断 ErrUnknownField、ErrFieldNotAllowed
+type FieldError struct {
+	Table string
+	Field string
+	Usage FieldUsage
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s.%s (%s)", e.Err.Error(), e.Table, e.Field, e.Usage)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// TablePolicy 表字段访问策略，用于限制 QueryRecord 可以使用的字段
+// 字段列表为 nil 时允许表中所有字段，为空列表时不允许任何字段
+type TablePolicy struct {
+	Queryable  []string // 可以查询返回的字段
+	Sortable   []string // 可以排序的字段
+	Filterable []string // 可以作为查询条件的字段
+}
+
+// allowed 返回对应用途允许的字段，nil 表示不限制
+func (p TablePolicy) allowed(usage FieldUsage) []string {
+	switch usage {
+	case FieldUsageQuery:
+		return p.Queryable
+	case FieldUsageSort:
+		return p.Sortable
+	default:
+		return p.Filterable
+	}
+}
+
+// UseTablePolicy 设置表字段访问策略
+func UseTablePolicy(tableName string, policy TablePolicy) {
+	global.mux.Lock()
+	defer global.mux.Unlock()
+	global.policyMap[tableName] = policy
+}
+
+// GetTablePolicy 根据表名获得字段访问策略
+func GetTablePolicy(tableName string) (TablePolicy, bool) {
+	global.mux.Lock()
+	defer global.mux.Unlock()
+	policy, ok := global.policyMap[tableName]
+	return policy, ok
+}
+
+// fieldValidator 根据表元信息和访问策略校验字段
+type fieldValidator struct {
+	table   string
+	meta    TableMeta
+	hasMeta bool // 没有元信息时不校验字段是否存在
+	policy  TablePolicy
+}
+
+// newFieldValidator 创建字段校验，表必须注册元信息
+func newFieldValidator(tableName string) (*fieldValidator, error) {
+	v, ok := lookupFieldValidator(tableName)
+	if !ok || !v.hasMeta {
+		return nil, fmt.Errorf("%w: %s", ErrTableNotRegistered, tableName)
+	}
+	return v, nil
+}
+
+// lookupFieldValidator 创建字段校验，表没有注册元信息和访问策略时返回 false，不需要校验
+func lookupFieldValidator(tableName string) (*fieldValidator, bool) {
+	meta, hasMeta := GetMetaInfo(tableName)
+	policy, hasPolicy := GetTablePolicy(tableName)
+	if !hasMeta && !hasPolicy {
+		return nil, false
+	}
+	return &fieldValidator{table: tableName, meta: meta, hasMeta: hasMeta, policy: policy}, true
+}
+
+func (v *fieldValidator) check(usage FieldUsage, fields ...string) error {
+	allowed := v.policy.allowed(usage)
+	for _, field := range fields {
+		if v.hasMeta && !utils.ContainsString(v.meta.Columns, field) {
+			return &FieldError{Table: v.table, Field: field, Usage: usage, Err: ErrUnknownField}
+		}
+		if allowed != nil && !utils.ContainsString(allowed, field) {
+			return &FieldError{Table: v.table, Field: field, Usage: usage, Err: ErrFieldNotAllowed}
+		}
+	}
+	return nil
+}
+
+// projection 投影字段，没有指定字段并且策略限制了查询字段时，只查询允许的字段
+func (v *fieldValidator) projection(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return v.policy.Queryable, nil
+	}
+	return fields, v.check(FieldUsageQuery, fields...)
+}
+
+func (v *fieldValidator) checkConditions(conditions []Condition) error {
+	for _, c := range conditions {
+		if err := v.check(FieldUsageFilter, c.Field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate 根据表元信息和访问策略校验查询字段、排序字段和条件字段，表没有注册元信息和访问策略时不校验
+func (q QueryRecord) Validate() error {
+	_, err := q.validated()
+	return err
+}
+
+// validated 校验查询参数，返回补全投影字段后的查询参数
+func (q QueryRecord) validated() (QueryRecord, error) {
+	v, ok := lookupFieldValidator(q.TableName)
+	if !ok {
+		return q, nil
+	}
+	var err error
+	if q.Fields, err = v.projection(q.Fields); err != nil {
+		return q, err
+	}
+	for _, orderField := range q.OrderFields {
+		if err = v.check(FieldUsageSort, orderField.Field); err != nil {
+			return q, err
+		}
+	}
+	return q, v.checkConditions(q.Conditions)
+}
+
+// Validate 根据表元信息和访问策略校验查询字段和条件字段，表没有注册元信息和访问策略时不校验
+func (r GetRecord) Validate() error {
+	_, err := r.validated()
+	return err
+}
+
+func (r GetRecord) validated() (GetRecord, error) {
+	v, ok := lookupFieldValidator(r.TableName)
+	if !ok {
+		return r, nil
+	}
+	var err error
+	if r.Fields, err = v.projection(r.Fields); err != nil {
+		return r, err
+	}
+	return r, v.checkConditions(r.Conditions)
+}
@@ -2,14 +2,13 @@ package daox
 
 import (
 	"context"
-	"reflect"
+	"database/sql"
 
 	"github.com/jmoiron/sqlx"
 
 	"github.com/fengjx/daox/engine"
 	"github.com/fengjx/daox/sqlbuilder"
 	"github.com/fengjx/daox/sqlbuilder/ql"
-	"github.com/fengjx/daox/types"
 )
 
 const (
@@ -27,6 +26,15 @@ const (
 	ConditionTypeGte     ConditionType = "gte"      // 大于等于
 	ConditionTypeLte     ConditionType = "lte"      // 小于等于
 
+	ConditionTypeBetween      ConditionType = "between"       // between，两个参数
+	ConditionTypeIsNull       ConditionType = "is_null"       // is null，没有参数
+	ConditionTypeIsNotNull    ConditionType = "is_not_null"   // is not null，没有参数
+	ConditionTypePrefix       ConditionType = "prefix"        // 前缀匹配，参数中的通配符会被转义
+	ConditionTypeSuffix       ConditionType = "suffix"        // 后缀匹配，参数中的通配符会被转义
+	ConditionTypeContains     ConditionType = "contains"      // 包含，参数中的通配符会被转义
+	ConditionTypeJSONContains ConditionType = "json_contains" // json 包含，仅支持 mysql
+	ConditionTypeRegex        ConditionType = "regex"         // 正则匹配，仅支持 mysql
+
 	OrderTypeAsc  OrderType = "asc"  // 升序
 	OrderTypeDesc OrderType = "desc" // 降序
 )
@@ -73,20 +81,30 @@ type Op string
 
 // ToSQLArgs 返回 sql 语句和参数
 func (q QueryRecord) ToSQLArgs() (sql string, args []any, err error) {
-	selector := q.buildSelector()
+	selector, err := q.buildSelector()
+	if err != nil {
+		return "", nil, err
+	}
 	return selector.SQLArgs()
 }
 
 // ToCountSQLArgs 返回 count 查询 sql 语句和参数
 func (q QueryRecord) ToCountSQLArgs() (sql string, args []any, err error) {
-	selector := q.buildSelector()
+	selector, err := q.buildSelector()
+	if err != nil {
+		return "", nil, err
+	}
 	return selector.CountSQLArgs()
 }
 
-func (q QueryRecord) buildSelector() *sqlbuilder.Selector {
+func (q QueryRecord) buildSelector() (*sqlbuilder.Selector, error) {
+	where, err := buildCondition(q.Conditions)
+	if err != nil {
+		return nil, err
+	}
 	selector := sqlbuilder.NewSelector(q.TableName)
 	selector.Columns(q.Fields...)
-	selector.Where(buildCondition(q.Conditions))
+	selector.Where(where)
 	if q.Page != nil {
 		selector.Offset(q.Page.Offset).Limit(q.Page.Limit)
 	}
@@ -102,63 +120,16 @@ func (q QueryRecord) buildSelector() *sqlbuilder.Selector {
 		}
 		selector.OrderBy(orderBy...)
 	}
-	return selector
-}
-
-func buildCondition(conditions []Condition) sqlbuilder.ConditionBuilder {
-	where := ql.C()
-	for _, c := range conditions {
-		if c.Disable {
-			continue
-		}
-		switch {
-		case c.ConditionType == ConditionTypeEq && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).EQ(c.Vals[0]))
-		case c.ConditionType == ConditionTypeEq && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).EQ(c.Vals[0]))
-		case c.ConditionType == ConditionTypeNotEq && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).NotEQ(c.Vals[0]))
-		case c.ConditionType == ConditionTypeNotEq && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).NotEQ(c.Vals[0]))
-		case c.ConditionType == ConditionTypeLike && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).Like(c.Vals[0]))
-		case c.ConditionType == ConditionTypeLike && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).Like(c.Vals[0]))
-		case c.ConditionType == ConditionTypeNotLike && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).NotLike(c.Vals[0]))
-		case c.ConditionType == ConditionTypeNotLike && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).NotLike(c.Vals[0]))
-		case c.ConditionType == ConditionTypeIn && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).In(c.Vals...))
-		case c.ConditionType == ConditionTypeIn && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).In(c.Vals...))
-		case c.ConditionType == ConditionTypeNotIn && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).NotIn(c.Vals...))
-		case c.ConditionType == ConditionTypeNotIn && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).NotIn(c.Vals...))
-		case c.ConditionType == ConditionTypeGt && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).GT(c.Vals[0]))
-		case c.ConditionType == ConditionTypeGt && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).GT(c.Vals[0]))
-		case c.ConditionType == ConditionTypeLt && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).LT(c.Vals[0]))
-		case c.ConditionType == ConditionTypeLt && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).LT(c.Vals[0]))
-		case c.ConditionType == ConditionTypeGte && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).GTEQ(c.Vals[0]))
-		case c.ConditionType == ConditionTypeGte && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).GTEQ(c.Vals[0]))
-		case c.ConditionType == ConditionTypeLte && c.Op == OpAnd:
-			where.And(ql.Col(c.Field).LTEQ(c.Vals[0]))
-		case c.ConditionType == ConditionTypeLte && c.Op == OpOr:
-			where.Or(ql.Col(c.Field).LTEQ(c.Vals[0]))
-		}
-	}
-	return where
+	return selector, nil
 }
 
 // Find 通用查询封装
+// 查询前会根据表元信息和访问策略校验字段，参考 QueryRecord.Validate
 func Find[T any](ctx context.Context, queryer engine.Queryer, query QueryRecord) (list []T, page *Page, err error) {
+	query, err = query.validated()
+	if err != nil {
+		return nil, query.Page, err
+	}
 	sql, args, err := query.ToSQLArgs()
 	if err != nil {
 		return nil, query.Page, err
@@ -168,8 +139,11 @@ func Find[T any](ctx context.Context, queryer engine.Queryer, query QueryRecord)
 		return nil, query.Page, err
 	}
 	page = query.Page
+	if page == nil {
+		return
+	}
 	page.Offset += int64(len(list))
-	if query.Page != nil && query.Page.QueryCount {
+	if page.QueryCount {
 		count, err := getCount(ctx, queryer, query)
 		if err != nil {
 			return nil, query.Page, err
@@ -181,7 +155,13 @@ func Find[T any](ctx context.Context, queryer engine.Queryer, query QueryRecord)
 }
 
 // FindListMap 通用查询封装，返回 map 类型
-func FindListMap(ctx context.Context, queryer engine.Queryer, query QueryRecord) (list []map[string]any, page *Page, err error) {
+// 查询前会根据表元信息和访问策略校验字段，参考 QueryRecord.Validate
+// 字段值的类型参考 ScanMap
+func FindListMap(ctx context.Context, queryer engine.Queryer, query QueryRecord, opts ...ScanOption) (list []map[string]any, page *Page, err error) {
+	query, err = query.validated()
+	if err != nil {
+		return nil, query.Page, err
+	}
 	sql, args, err := query.ToSQLArgs()
 	if err != nil {
 		return nil, query.Page, err
@@ -191,33 +171,16 @@ func FindListMap(ctx context.Context, queryer engine.Queryer, query QueryRecord)
 		return nil, query.Page, err
 	}
 	defer rows.Close()
-	for rows.Next() {
-		columns, err := rows.Columns()
-		if err != nil {
-			return nil, query.Page, err
-		}
-		colTypes, err := rows.ColumnTypes()
-		if err != nil {
-			return nil, query.Page, err
-		}
-		values := make([]any, len(colTypes))
-		for i, typ := range colTypes {
-			t := types.SQLType2GolangType(typ.DatabaseTypeName())
-			values[i] = reflect.New(t).Interface()
-		}
-		err = rows.Scan(values...)
-		if err != nil {
-			return nil, query.Page, err
-		}
-		data := make(map[string]any)
-		for i, column := range columns {
-			data[column] = reflect.ValueOf(values[i]).Elem().Interface()
-		}
-		list = append(list, data)
+	list, err = ScanMap(rows, opts...)
+	if err != nil {
+		return nil, query.Page, err
 	}
 	page = query.Page
+	if page == nil {
+		return
+	}
 	page.Offset += int64(len(list))
-	if query.Page != nil && query.Page.QueryCount {
+	if page.QueryCount {
 		count, err := getCount(ctx, queryer, query)
 		if err != nil {
 			return nil, query.Page, err
@@ -252,14 +215,23 @@ type GetRecord struct {
 
 // ToSQLArgs 返回 sql 语句和参数
 func (r GetRecord) ToSQLArgs() (sql string, args []any, err error) {
+	where, err := buildCondition(r.Conditions)
+	if err != nil {
+		return "", nil, err
+	}
 	selector := sqlbuilder.NewSelector(r.TableName)
 	selector.Columns(r.Fields...)
-	selector.Where(buildCondition(r.Conditions))
+	selector.Where(where)
 	return selector.SQLArgs()
 }
 
 // Get 查询单条记录
+// 查询前会根据表元信息和访问策略校验字段，参考 GetRecord.Validate
 func Get[T any](ctx context.Context, dbx *sqlx.DB, record GetRecord) (*T, error) {
+	record, err := record.validated()
+	if err != nil {
+		return nil, err
+	}
 	sql, args, err := record.ToSQLArgs()
 	if err != nil {
 		return nil, err
@@ -272,33 +244,32 @@ func Get[T any](ctx context.Context, dbx *sqlx.DB, record GetRecord) (*T, error)
 	return data, nil
 }
 
-// GetMap 查询单条记录，返回 map
-func GetMap(ctx context.Context, dbx *sqlx.DB, record GetRecord) (map[string]any, error) {
-	sql, args, err := record.ToSQLArgs()
+// GetMap 查询单条记录，返回 map，没有记录时返回 sql.ErrNoRows
+// 查询前会根据表元信息和访问策略校验字段，参考 GetRecord.Validate
+// 字段值的类型参考 ScanMap
+func GetMap(ctx context.Context, dbx *sqlx.DB, record GetRecord, opts ...ScanOption) (map[string]any, error) {
+	record, err := record.validated()
 	if err != nil {
 		return nil, err
 	}
-	row := dbx.QueryRowxContext(ctx, sql, args...)
-	columns, err := row.Columns()
+	query, args, err := record.ToSQLArgs()
 	if err != nil {
 		return nil, err
 	}
-	colTypes, err := row.ColumnTypes()
+	rows, err := dbx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	values := make([]any, len(colTypes))
-	for i, typ := range colTypes {
-		t := types.SQLType2GolangType(typ.DatabaseTypeName())
-		values[i] = reflect.New(t).Interface()
-	}
-	err = row.Scan(values...)
+	defer rows.Close()
+	scanner, err := newMapScanner(rows, opts...)
 	if err != nil {
 		return nil, err
 	}
-	data := make(map[string]any)
-	for i, column := range columns {
-		data[column] = reflect.ValueOf(values[i]).Elem().Interface()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
 	}
-	return data, nil
+	return scanner.scan()
 }
@@ -2,6 +2,7 @@ package daox_test
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -152,6 +153,93 @@ func TestGetMap(t *testing.T) {
 		t.Fatal(err)
 	}
 	t.Log("data", data)
-	assert.Equal(t, int32(1), data["id"])
-	assert.Equal(t, int32(100), data["uid"])
+	assert.Equal(t, int64(1), data["id"])
+	assert.Equal(t, int64(100), data["uid"])
+	assert.Equal(t, "u-0", data["name"])
+
+	record.Conditions[0].Vals = []any{100}
+	_, err = daox.GetMap(ctx, newDb(), record)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueryRecordValidate(t *testing.T) {
+	ctx := context.Background()
+	tableName := "test_query_policy_info"
+	before(t, tableName)
+	db := newDb()
+
+	q := daox.QueryRecord{
+		TableName:   tableName,
+		Fields:      []string{"id", "name"},
+		OrderFields: []daox.OrderField{{Field: "id", OrderType: daox.OrderTypeDesc}},
+		Page:        &daox.Page{Limit: 2},
+	}
+	assert.NoError(t, q.Validate())
+
+	// 没有注册元信息和访问策略的表不校验字段
+	_, err := db.Exec("DROP TABLE IF EXISTS query_not_registered")
+	assert.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE query_not_registered (id integer primary key autoincrement, name text)")
+	assert.NoError(t, err)
+	defer db.Exec("DROP TABLE IF EXISTS query_not_registered")
+	_, err = db.Exec("INSERT INTO query_not_registered (name) VALUES ('n-1')")
+	assert.NoError(t, err)
+	list, _, err := daox.FindListMap(ctx, db, daox.QueryRecord{TableName: "query_not_registered", Fields: []string{"name"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"name": "n-1"}}, list)
+	row, err := daox.GetMap(ctx, db, daox.GetRecord{TableName: "query_not_registered"})
+	assert.NoError(t, err)
+	assert.Equal(t, "n-1", row["name"])
+	// 只注册访问策略时只校验策略
+	daox.UseTablePolicy("query_not_registered", daox.TablePolicy{Queryable: []string{"id"}})
+	_, _, err = daox.FindListMap(ctx, db, daox.QueryRecord{TableName: "query_not_registered", Fields: []string{"name"}})
+	assert.ErrorIs(t, err, daox.ErrFieldNotAllowed)
+
+	q.Fields = []string{"id", "name`, (select 1) as `x"}
+	_, _, err = daox.FindListMap(ctx, db, q)
+	assert.ErrorIs(t, err, daox.ErrUnknownField)
+	var fieldErr *daox.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, daox.FieldUsageQuery, fieldErr.Usage)
+
+	daox.UseTablePolicy(tableName, daox.TablePolicy{
+		Queryable:  []string{"id", "name"},
+		Sortable:   []string{"id"},
+		Filterable: []string{"uid"},
+	})
+	q.Fields = nil
+	q.OrderFields = []daox.OrderField{{Field: "ctime", OrderType: daox.OrderTypeDesc}}
+	err = q.Validate()
+	assert.ErrorIs(t, err, daox.ErrFieldNotAllowed)
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, daox.FieldUsageSort, fieldErr.Usage)
+	assert.Equal(t, "ctime", fieldErr.Field)
+
+	q.OrderFields = nil
+	q.Conditions = []daox.Condition{{Op: daox.OpAnd, ConditionType: daox.ConditionTypeEq, Field: "name", Vals: []any{"u-1"}}}
+	assert.ErrorIs(t, q.Validate(), daox.ErrFieldNotAllowed)
+
+	// 没有指定投影字段时只查询允许的字段
+	q.Conditions = []daox.Condition{{Op: daox.OpAnd, ConditionType: daox.ConditionTypeGte, Field: "uid", Vals: []any{100}}}
+	list, _, err = daox.FindListMap(ctx, db, q)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Len(t, list[0], 2)
+
+	_, err = daox.GetMap(ctx, db, daox.GetRecord{TableName: tableName, Fields: []string{"sex"}})
+	assert.ErrorIs(t, err, daox.ErrFieldNotAllowed)
+}
+
+func TestFindWithoutPage(t *testing.T) {
+	tableName := "test_find_without_page"
+	before(t, tableName)
+	query := daox.QueryRecord{TableName: tableName}
+	list, page, err := daox.Find[DemoInfo](context.Background(), newDb(), query)
+	assert.NoError(t, err)
+	assert.Len(t, list, 10)
+	assert.Nil(t, page)
+	mapList, page, err := daox.FindListMap(context.Background(), newDb(), query)
+	assert.NoError(t, err)
+	assert.Len(t, mapList, 10)
+	assert.Nil(t, page)
 }
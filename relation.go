@@ -0,0 +1,244 @@
+package daox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fengjx/daox/sqlbuilder/ql"
+	"github.com/fengjx/daox/utils"
+)
+
+// RelTagName 关联字段的 tag 名，eg: `rel:"comments"`
+const RelTagName = "rel"
+
+// ErrRelationNotFound 预加载的关联关系未定义
+var ErrRelationNotFound = errors.New("[daox] relation not found")
+
+type relationType string
+
+const (
+	relationHasOne    relationType = "has_one"
+	relationHasMany   relationType = "has_many"
+	relationBelongsTo relationType = "belongs_to"
+)
+
+// relation 表关联关系
+type relation struct {
+	name       string
+	typ        relationType
+	target     *Dao
+	foreignKey string
+}
+
+// HasOne 定义一对一关联，foreignKey 为关联表中指向当前表主键的字段
+// 关联数据赋值到 tag 为 `rel:"name"` 的字段，字段类型为结构体或结构体指针
+func (d *Dao) HasOne(name string, target *Dao, foreignKey string) *Dao {
+	return d.addRelation(name, relationHasOne, target, foreignKey)
+}
+
+// HasMany 定义一对多关联，foreignKey 为关联表中指向当前表主键的字段
+// 关联数据赋值到 tag 为 `rel:"name"` 的字段，字段类型为切片
+func (d *Dao) HasMany(name string, target *Dao, foreignKey string) *Dao {
+	return d.addRelation(name, relationHasMany, target, foreignKey)
+}
+
+// BelongsTo 定义从属关联，foreignKey 为当前表中指向关联表主键的字段
+// 关联数据赋值到 tag 为 `rel:"name"` 的字段，字段类型为结构体或结构体指针
+func (d *Dao) BelongsTo(name string, target *Dao, foreignKey string) *Dao {
+	return d.addRelation(name, relationBelongsTo, target, foreignKey)
+}
+
+func (d *Dao) addRelation(name string, typ relationType, target *Dao, foreignKey string) *Dao {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.relations == nil {
+		d.relations = make(map[string]*relation)
+	}
+	d.relations[name] = &relation{
+		name:       name,
+		typ:        typ,
+		target:     target,
+		foreignKey: foreignKey,
+	}
+	return d
+}
+
+// Preload 返回查询时预加载关联数据的 dao，支持嵌套路径，eg: comments.user
+// 对 GetByColumn、GetByID、List、ListByColumns、ListByIDs 等查询方法生效
+func (d *Dao) Preload(paths ...string) *Dao {
+	newDao := d.WithExecutor(d.executor)
+	newDao.preloads = append(append([]string{}, d.preloads...), paths...)
+	return newDao
+}
+
+// LoadRelations 加载关联数据，每个关联关系执行一次 IN 查询
+// dest 为结构体指针或切片指针，paths 为关联名称，支持嵌套路径，eg: comments.user
+func (d *Dao) LoadRelations(ctx context.Context, dest any, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	parents := structValues(reflect.ValueOf(dest))
+	if len(parents) == 0 {
+		return nil
+	}
+	var names []string
+	nested := make(map[string][]string)
+	for _, path := range paths {
+		name, sub, _ := strings.Cut(path, ".")
+		if _, ok := nested[name]; !ok {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if sub != "" {
+			nested[name] = append(nested[name], sub)
+		}
+	}
+	for _, name := range names {
+		rel, ok := d.relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s.%s", ErrRelationNotFound, d.TableName(), name)
+		}
+		if err := d.loadRelation(ctx, rel, parents, nested[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRelations 查询方法执行完后预加载关联数据
+func (d *Dao) loadRelations(ctx context.Context, dest any) error {
+	if len(d.preloads) == 0 {
+		return nil
+	}
+	return d.LoadRelations(ctx, dest, d.preloads...)
+}
+
+func (d *Dao) loadRelation(ctx context.Context, rel *relation, parents []reflect.Value, subPaths []string) error {
+	field, ok := relationField(parents[0].Type(), rel.name)
+	if !ok {
+		return fmt.Errorf("[daox] field with tag `%s:\"%s\"` not found in %s", RelTagName, rel.name, parents[0].Type())
+	}
+	// 当前表中用于关联的字段，和关联表中对应的字段
+	localKey, targetKey := d.TableMeta.PrimaryKey, rel.foreignKey
+	if rel.typ == relationBelongsTo {
+		localKey, targetKey = rel.foreignKey, rel.target.TableMeta.PrimaryKey
+	}
+	var keys []any
+	exists := make(map[string]bool)
+	for _, parent := range parents {
+		key, ok := columnValue(d, parent, localKey)
+		if !ok {
+			continue
+		}
+		k := utils.ToString(key)
+		if exists[k] {
+			continue
+		}
+		exists[k] = true
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	elemType := field.Type
+	if rel.typ == relationHasMany {
+		if elemType.Kind() != reflect.Slice {
+			return fmt.Errorf("[daox] relation field %s must be slice", field.Name)
+		}
+		elemType = elemType.Elem()
+	}
+	children := reflect.New(reflect.SliceOf(elemType))
+	err := rel.target.Selector().
+		Where(ql.C(ql.Col(targetKey).In(keys...))).
+		SelectContext(ctx, children.Interface())
+	if err != nil {
+		return err
+	}
+	if len(subPaths) > 0 {
+		if err = rel.target.LoadRelations(ctx, children.Interface(), subPaths...); err != nil {
+			return err
+		}
+	}
+	// 按关联字段分组
+	groups := make(map[string][]reflect.Value)
+	list := children.Elem()
+	for i := 0; i < list.Len(); i++ {
+		child := list.Index(i)
+		key, ok := columnValue(rel.target, reflect.Indirect(child), targetKey)
+		if !ok {
+			continue
+		}
+		k := utils.ToString(key)
+		groups[k] = append(groups[k], child)
+	}
+	for _, parent := range parents {
+		key, ok := columnValue(d, parent, localKey)
+		if !ok {
+			continue
+		}
+		items := groups[utils.ToString(key)]
+		fv := parent.FieldByIndex(field.Index)
+		if rel.typ == relationHasMany {
+			slice := reflect.MakeSlice(field.Type, 0, len(items))
+			slice = reflect.Append(slice, items...)
+			fv.Set(slice)
+			continue
+		}
+		if len(items) > 0 {
+			fv.Set(items[0])
+		}
+	}
+	return nil
+}
+
+// relationField 查找 tag 为 `rel:"name"` 的字段
+func relationField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Tag.Get(RelTagName) == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// columnValue 获取结构体中数据库字段的值，值为 nil 时返回 false
+func columnValue(d *Dao, v reflect.Value, col string) (any, bool) {
+	fv := d.mapper.FieldByName(v, col)
+	if !fv.IsValid() {
+		return nil, false
+	}
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil, false
+		}
+		fv = fv.Elem()
+	}
+	return fv.Interface(), true
+}
+
+// structValues 获取结构体、结构体切片中所有可以赋值的结构体
+func structValues(v reflect.Value) []reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.CanAddr() {
+			return []reflect.Value{v}
+		}
+	case reflect.Slice, reflect.Array:
+		var values []reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, structValues(v.Index(i).Addr())...)
+		}
+		return values
+	}
+	return nil
+}
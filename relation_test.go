@@ -0,0 +1,120 @@
+package daox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+)
+
+type relUser struct {
+	ID   int64    `json:"id"`
+	Name string   `json:"name"`
+	Blog *relBlog `json:"-" rel:"blog"`
+}
+
+func (m *relUser) GetID() any {
+	return m.ID
+}
+
+type relBlog struct {
+	ID       int64         `json:"id"`
+	UID      int64         `json:"uid"`
+	Title    string        `json:"title"`
+	Author   *relUser      `json:"author" rel:"author"`
+	Comments []*relComment `json:"comments" rel:"comments"`
+}
+
+func (m *relBlog) GetID() any {
+	return m.ID
+}
+
+type relComment struct {
+	ID      int64   `json:"id"`
+	BlogID  int64   `json:"blog_id"`
+	UID     int64   `json:"uid"`
+	Content string  `json:"content"`
+	User    relUser `json:"-" rel:"user"`
+}
+
+func (m *relComment) GetID() any {
+	return m.ID
+}
+
+func TestRelation(t *testing.T) {
+	ctx := context.Background()
+	db := newDb()
+	tables := map[string]string{
+		"rel_user":    "CREATE TABLE rel_user (id integer primary key autoincrement, name text)",
+		"rel_blog":    "CREATE TABLE rel_blog (id integer primary key autoincrement, uid integer, title text)",
+		"rel_comment": "CREATE TABLE rel_comment (id integer primary key autoincrement, blog_id integer, uid integer, content text)",
+	}
+	for name, ddl := range tables {
+		after(t, name)
+		_, err := db.Exec(ddl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer after(t, name)
+	}
+	userDao := daox.NewDao[*relUser]("rel_user", "id", daox.IsAutoIncrement(), daox.WithDBMaster(db))
+	blogDao := daox.NewDao[*relBlog]("rel_blog", "id", daox.IsAutoIncrement(), daox.WithDBMaster(db))
+	commentDao := daox.NewDao[*relComment]("rel_comment", "id", daox.IsAutoIncrement(), daox.WithDBMaster(db))
+	assert.Equal(t, []string{"id", "uid", "title"}, blogDao.TableMeta.Columns)
+
+	userDao.HasOne("blog", blogDao, "uid")
+	blogDao.BelongsTo("author", userDao, "uid").
+		HasMany("comments", commentDao, "blog_id")
+	commentDao.BelongsTo("user", userDao, "uid")
+
+	for _, name := range []string{"u1", "u2", "u3"} {
+		_, err := userDao.Save(&relUser{Name: name})
+		assert.NoError(t, err)
+	}
+	_, err := blogDao.BatchSave([]*relBlog{
+		{UID: 1, Title: "b1"},
+		{UID: 2, Title: "b2"},
+		{UID: 1, Title: "b3"},
+	})
+	assert.NoError(t, err)
+	_, err = commentDao.BatchSave([]*relComment{
+		{BlogID: 1, UID: 2, Content: "c1"},
+		{BlogID: 1, UID: 3, Content: "c2"},
+		{BlogID: 2, UID: 1, Content: "c3"},
+	})
+	assert.NoError(t, err)
+
+	var blogs []*relBlog
+	err = blogDao.Preload("author", "comments.user").ListByIDsContext(ctx, &blogs, 1, 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(blogs))
+	assert.Equal(t, "u1", blogs[0].Author.Name)
+	assert.Equal(t, "u2", blogs[1].Author.Name)
+	assert.Equal(t, 2, len(blogs[0].Comments))
+	assert.Equal(t, "u2", blogs[0].Comments[0].User.Name)
+	assert.Equal(t, "u3", blogs[0].Comments[1].User.Name)
+	assert.Equal(t, 1, len(blogs[1].Comments))
+	assert.Equal(t, 0, len(blogs[2].Comments))
+
+	user := &relUser{}
+	exist, err := userDao.Preload("blog.comments").GetByIDContext(ctx, 2, user)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, "b2", user.Blog.Title)
+	assert.Equal(t, "c3", user.Blog.Comments[0].Content)
+
+	// 没有预加载
+	user = &relUser{}
+	_, err = userDao.GetByIDContext(ctx, 3, user)
+	assert.NoError(t, err)
+	assert.Nil(t, user.Blog)
+	err = userDao.LoadRelations(ctx, user, "blog")
+	assert.NoError(t, err)
+	assert.Nil(t, user.Blog)
+
+	err = userDao.LoadRelations(ctx, user, "not_exist")
+	assert.True(t, errors.Is(err, daox.ErrRelationNotFound))
+}
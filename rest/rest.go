@@ -0,0 +1,291 @@
+// Package rest 基于表元信息的通用 CRUD http 接口
+// 只有通过 NewDao 或 NewDaoByMeta 注册了元信息，并且通过 WithTable 授权的表才可以访问
+//
+//	GET    /{table}       列表查询，查询参数参考 daox.ParseFilter
+//	GET    /{table}/{id}  根据主键查询
+//	POST   /{table}       新增
+//	PUT    /{table}/{id}  根据主键修改
+//	DELETE /{table}/{id}  根据主键删除
+package rest
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/utils"
+)
+
+var (
+	ErrTableNotFound = errors.New("[rest] table not found")
+	ErrForbidden     = errors.New("[rest] permission denied")
+	ErrBadRequest    = errors.New("[rest] bad request")
+)
+
+// Permission 表访问权限
+type Permission uint8
+
+const (
+	PermRead      Permission = 1 << iota // 查询
+	PermWrite                            // 新增、修改、删除
+	PermReadWrite = PermRead | PermWrite
+)
+
+// PageResult 列表查询结果
+type PageResult struct {
+	List []map[string]any `json:"list"`
+	Page *daox.Page       `json:"page"`
+}
+
+// ErrorResult 错误信息
+type ErrorResult struct {
+	Error string `json:"error"`
+}
+
+type Options struct {
+	perms      map[string]Permission
+	filterOpts []daox.FilterOption
+}
+
+type Option func(*Options)
+
+// WithTable 授权访问表，没有授权的表返回 404
+func WithTable(tableName string, perm Permission) Option {
+	return func(o *Options) {
+		o.perms[tableName] = perm
+	}
+}
+
+// WithFilterOptions 设置列表查询参数解析选项
+func WithFilterOptions(opts ...daox.FilterOption) Option {
+	return func(o *Options) {
+		o.filterOpts = append(o.filterOpts, opts...)
+	}
+}
+
+// Handler 通用 CRUD http 接口
+type Handler struct {
+	db         *sqlx.DB
+	perms      map[string]Permission
+	filterOpts []daox.FilterOption
+	mux        *http.ServeMux
+}
+
+// New 创建通用 CRUD http 接口，挂载到子路径时可以使用 http.StripPrefix
+func New(db *sqlx.DB, opts ...Option) *Handler {
+	options := &Options{perms: make(map[string]Permission)}
+	for _, opt := range opts {
+		opt(options)
+	}
+	h := &Handler{
+		db:         db,
+		perms:      options.perms,
+		filterOpts: options.filterOpts,
+		mux:        http.NewServeMux(),
+	}
+	h.mux.HandleFunc("GET /{table}", h.list)
+	h.mux.HandleFunc("GET /{table}/{id}", h.get)
+	h.mux.HandleFunc("POST /{table}", h.create)
+	h.mux.HandleFunc("PUT /{table}/{id}", h.update)
+	h.mux.HandleFunc("DELETE /{table}/{id}", h.delete)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.table(r, PermRead)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	query, err := daox.ParseFilter(meta.TableName, r.URL.Query(), h.filterOpts...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	list, page, err := daox.FindListMap(r.Context(), h.db, query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if list == nil {
+		list = []map[string]any{}
+	}
+	writeJSON(w, http.StatusOK, PageResult{List: list, Page: page})
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.table(r, PermRead)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var fields []string
+	if val := r.URL.Query().Get(daox.FilterKeyFields); val != "" {
+		fields = strings.Split(val, ",")
+	}
+	data, err := daox.GetMap(r.Context(), h.db, daox.GetRecord{
+		TableName:  meta.TableName,
+		Fields:     fields,
+		Conditions: pkConditions(meta, r),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.table(r, PermWrite)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	row, err := readRow(r, meta)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	id, err := daox.Insert(r.Context(), h.db, daox.InsertRecord{TableName: meta.TableName, Row: row})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.table(r, PermWrite)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	row, err := readRow(r, meta)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// 主键不允许修改
+	delete(row, meta.PrimaryKey)
+	if len(row) == 0 {
+		writeError(w, fmt.Errorf("%w: empty row", ErrBadRequest))
+		return
+	}
+	affected, err := daox.Update(r.Context(), h.db, daox.UpdateRecord{
+		TableName:  meta.TableName,
+		Row:        row,
+		Conditions: pkConditions(meta, r),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"affected": affected})
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	meta, err := h.table(r, PermWrite)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	affected, err := daox.Delete(r.Context(), h.db, daox.DeleteRecord{
+		TableName:  meta.TableName,
+		Conditions: pkConditions(meta, r),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"affected": affected})
+}
+
+// table 校验表授权，返回表元信息
+func (h *Handler) table(r *http.Request, perm Permission) (daox.TableMeta, error) {
+	tableName := r.PathValue("table")
+	granted, ok := h.perms[tableName]
+	if !ok {
+		return daox.TableMeta{}, fmt.Errorf("%w: %s", ErrTableNotFound, tableName)
+	}
+	meta, ok := daox.GetMetaInfo(tableName)
+	if !ok {
+		return daox.TableMeta{}, fmt.Errorf("%w: %s", ErrTableNotFound, tableName)
+	}
+	if granted&perm != perm {
+		return daox.TableMeta{}, fmt.Errorf("%w: %s", ErrForbidden, tableName)
+	}
+	return meta, nil
+}
+
+func pkConditions(meta daox.TableMeta, r *http.Request) []daox.Condition {
+	return []daox.Condition{
+		{Op: daox.OpAnd, Field: meta.PrimaryKey, Vals: []any{r.PathValue("id")}, ConditionType: daox.ConditionTypeEq},
+	}
+}
+
+// readRow 读取请求体中的行数据，只允许表中的字段
+func readRow(r *http.Request, meta daox.TableMeta) (map[string]any, error) {
+	row := make(map[string]any)
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&row); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadRequest, err)
+	}
+	for col := range row {
+		if !utils.ContainsString(meta.Columns, col) {
+			return nil, fmt.Errorf("%w: %s.%s", daox.ErrUnknownField, meta.TableName, col)
+		}
+	}
+	return row, nil
+}
+
+// statusCode 根据错误类型返回 http 状态码
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrTableNotFound), errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, ErrForbidden), errors.Is(err, daox.ErrFieldNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, ErrBadRequest),
+		errors.Is(err, daox.ErrUnknownField),
+		errors.Is(err, daox.ErrInvalidFilter),
+		errors.Is(err, daox.ErrConditionType),
+		errors.Is(err, daox.ErrConditionVals):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := statusCode(err)
+	msg := err.Error()
+	if code == http.StatusInternalServerError {
+		// 不暴露数据库错误信息
+		msg = http.StatusText(code)
+	}
+	writeJSON(w, code, ErrorResult{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, code int, data any) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		code = http.StatusInternalServerError
+		buf.Reset()
+		buf.WriteString(`{"error":"` + http.StatusText(code) + `"}` + "\n")
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,107 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/rest"
+)
+
+type restUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Age  int64  `json:"age"`
+}
+
+func (m *restUser) GetID() any {
+	return m.ID
+}
+
+func newServer(t *testing.T, opts ...rest.Option) *httptest.Server {
+	db := sqlx.MustOpen("sqlite3", filepath.Join(t.TempDir(), "rest.db"))
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	dao := daox.NewDao[*restUser]("rest_user", "id", daox.IsAutoIncrement(), daox.WithDBMaster(db))
+	assert.NoError(t, daox.AutoMigrate(context.Background(), db, dao.TableMeta))
+	daox.NewDao[*restUser]("rest_blog", "id", daox.WithDBMaster(db))
+	server := httptest.NewServer(http.StripPrefix("/api", rest.New(db, opts...)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doRequest(t *testing.T, method, url, body string, result any) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	if result != nil {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+	}
+	return resp.StatusCode
+}
+
+func TestHandler(t *testing.T) {
+	server := newServer(t, rest.WithTable("rest_user", rest.PermReadWrite))
+	api := server.URL + "/api/rest_user"
+
+	var created map[string]int64
+	for _, body := range []string{`{"name": "u1", "age": 18}`, `{"name": "u2", "age": 20}`, `{"name": "u3", "age": 30}`} {
+		assert.Equal(t, http.StatusCreated, doRequest(t, http.MethodPost, api, body, &created))
+	}
+	assert.Equal(t, int64(3), created["id"])
+
+	var page rest.PageResult
+	assert.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, api+"?age__gte=20&sort=-age&limit=1&count=true", "", &page))
+	assert.Len(t, page.List, 1)
+	assert.Equal(t, "u3", page.List[0]["name"])
+	assert.Equal(t, &daox.Page{Offset: 1, Limit: 1, Count: 2, HasNext: true, QueryCount: true}, page.Page)
+
+	var affected map[string]int64
+	assert.Equal(t, http.StatusOK, doRequest(t, http.MethodPut, api+"/2", `{"age": 21}`, &affected))
+	assert.Equal(t, int64(1), affected["affected"])
+
+	var user map[string]any
+	assert.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, api+"/2?fields=name,age", "", &user))
+	assert.Equal(t, map[string]any{"name": "u2", "age": float64(21)}, user)
+
+	assert.Equal(t, http.StatusOK, doRequest(t, http.MethodDelete, api+"/2", "", &affected))
+	assert.Equal(t, int64(1), affected["affected"])
+
+	var errResult rest.ErrorResult
+	assert.Equal(t, http.StatusNotFound, doRequest(t, http.MethodGet, api+"/2", "", &errResult))
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, http.MethodGet, api+"?nickname=u1", "", &errResult))
+	assert.Contains(t, errResult.Error, "unknown field")
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, http.MethodPost, api, `{"nickname": "u4"}`, &errResult))
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, http.MethodPost, api, `{`, &errResult))
+}
+
+func TestHandlerPermission(t *testing.T) {
+	server := newServer(t, rest.WithTable("rest_user", rest.PermRead))
+	api := server.URL + "/api/"
+
+	var page rest.PageResult
+	assert.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, api+"rest_user", "", &page))
+	assert.Empty(t, page.List)
+
+	var errResult rest.ErrorResult
+	assert.Equal(t, http.StatusForbidden, doRequest(t, http.MethodPost, api+"rest_user", `{"name": "u1"}`, &errResult))
+	assert.Equal(t, http.StatusForbidden, doRequest(t, http.MethodDelete, api+"rest_user/1", "", &errResult))
+	// 已注册但是没有授权的表
+	assert.Equal(t, http.StatusNotFound, doRequest(t, http.MethodGet, api+"rest_blog", "", &errResult))
+	// 授权但是没有注册的表
+	server = newServer(t, rest.WithTable("not_registered", rest.PermReadWrite))
+	assert.Equal(t, http.StatusNotFound, doRequest(t, http.MethodGet, server.URL+"/api/not_registered", "", &errResult))
+}
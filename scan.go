@@ -0,0 +1,200 @@
+package daox
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fengjx/daox/utils"
+)
+
+// ColumnConverter 字段值转换函数，NULL 值不会调用转换函数
+type ColumnConverter func(val any) (any, error)
+
+var (
+	anyType      = reflect.TypeFor[any]()
+	rawBytesType = reflect.TypeFor[sql.RawBytes]()
+)
+
+// binaryTypes 二进制字段类型，扫描结果保持 []byte，其他类型的 []byte 会转换成字符串
+var binaryTypes = []string{"BLOB", "BINARY", "VARBINARY", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA"}
+
+// JSONConverter 把 json 字符串转换成 map、slice 等类型
+func JSONConverter(val any) (any, error) {
+	var data []byte
+	switch v := val.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return val, nil
+	}
+	var res any
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StringConverter 转换成字符串，可以用于 DECIMAL 等需要保持精度的字段
+func StringConverter(val any) (any, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// columnScanner 单个字段的扫描方式
+type columnScanner struct {
+	name      string
+	scanType  reflect.Type // nil 表示扫描到 any
+	binary    bool
+	converter ColumnConverter
+}
+
+// newTarget 创建扫描目标，非空类型使用二级指针，NULL 值会被扫描成 nil
+func (c columnScanner) newTarget() any {
+	if c.scanType == nil {
+		return new(any)
+	}
+	if isNullable(c.scanType) {
+		return reflect.New(c.scanType).Interface()
+	}
+	return reflect.New(reflect.PointerTo(c.scanType)).Interface()
+}
+
+// value 从扫描目标中取值
+func (c columnScanner) value(target any) (any, error) {
+	val := reflect.ValueOf(target).Elem()
+	var res any
+	switch {
+	case c.scanType == nil:
+		res = val.Interface()
+		if bs, ok := res.([]byte); ok && !c.binary {
+			res = string(bs)
+		}
+	case isNullable(c.scanType):
+		if !val.FieldByName("Valid").Bool() {
+			return nil, nil
+		}
+		res = val.Field(0).Interface()
+	default:
+		if val.IsNil() {
+			return nil, nil
+		}
+		res = val.Elem().Interface()
+	}
+	if res == nil || c.converter == nil {
+		return res, nil
+	}
+	converted, err := c.converter(res)
+	if err != nil {
+		return nil, fmt.Errorf("[daox] convert column %s: %w", c.name, err)
+	}
+	return converted, nil
+}
+
+// isNullable sql.NullInt64、sql.Null[T] 等包含 Valid 字段的类型
+func isNullable(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 2 {
+		return false
+	}
+	valid, ok := typ.FieldByName("Valid")
+	return ok && valid.Type.Kind() == reflect.Bool && valid.Index[0] == 1
+}
+
+// mapScanner 把查询结果扫描成 map，字段元信息在创建时读取一次
+type mapScanner struct {
+	rows    *sql.Rows
+	columns []columnScanner
+}
+
+func newMapScanner(rows *sql.Rows, opts ...ScanOption) (*mapScanner, error) {
+	options := &ScanOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]columnScanner, len(colTypes))
+	for i, colType := range colTypes {
+		// sqlite 返回声明的类型，eg: DECIMAL(10, 2)
+		dbType, _, _ := strings.Cut(strings.ToUpper(colType.DatabaseTypeName()), "(")
+		dbType = strings.TrimSpace(dbType)
+		col := columnScanner{
+			name:     colType.Name(),
+			scanType: colType.ScanType(),
+			binary:   utils.ContainsString(binaryTypes, dbType),
+		}
+		if typ := col.scanType; typ == nil || typ == rawBytesType || typ.Kind() == reflect.Interface ||
+			(typ.Kind() == reflect.Pointer && typ.Elem() == anyType) {
+			col.scanType = nil
+		}
+		if fn, ok := options.columnConverters[col.name]; ok {
+			col.converter = fn
+		} else if fn, ok = options.typeConverters[dbType]; ok {
+			col.converter = fn
+		}
+		columns[i] = col
+	}
+	return &mapScanner{rows: rows, columns: columns}, nil
+}
+
+func (s *mapScanner) scan() (map[string]any, error) {
+	values, err := s.scanValues()
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]any, len(s.columns))
+	for i, col := range s.columns {
+		data[col.name] = values[i]
+	}
+	return data, nil
+}
+
+// scanValues 扫描当前行，返回值与字段顺序一致
+func (s *mapScanner) scanValues() ([]any, error) {
+	targets := make([]any, len(s.columns))
+	for i, col := range s.columns {
+		targets[i] = col.newTarget()
+	}
+	if err := s.rows.Scan(targets...); err != nil {
+		return nil, err
+	}
+	values := make([]any, len(s.columns))
+	for i, col := range s.columns {
+		val, err := col.value(targets[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = val
+	}
+	return values, nil
+}
+
+// ScanMap 把查询结果扫描成 map 列表，不会关闭 rows
+// NULL 值为 nil，其他值的类型由驱动的 ScanType 决定，可以通过 WithColumnConverter、WithTypeConverter 转换字段值
+func ScanMap(rows *sql.Rows, opts ...ScanOption) ([]map[string]any, error) {
+	scanner, err := newMapScanner(rows, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var list []map[string]any
+	for rows.Next() {
+		data, err := scanner.scan()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, data)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,65 @@
+package daox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+)
+
+func TestScanMap(t *testing.T) {
+	ctx := context.Background()
+	db := newDb()
+	_, err := db.Exec(`DROP TABLE IF EXISTS test_scan_map`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE test_scan_map (
+  id integer primary key,
+  name varchar(32),
+  price decimal(10, 2),
+  attrs json,
+  data blob,
+  other
+)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO test_scan_map (id, name, price, attrs, data, other) VALUES
+  (1, 'u1', 12.5, '{"tags": ["a"]}', x'0102', 'text'),
+  (2, NULL, NULL, NULL, NULL, 3)`)
+	assert.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx, "SELECT id, name, price, attrs, data, other FROM test_scan_map ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	list, err := daox.ScanMap(rows,
+		daox.WithColumnConverter("attrs", daox.JSONConverter),
+		daox.WithTypeConverter("decimal", daox.StringConverter),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{
+			"id":    int64(1),
+			"name":  "u1",
+			"price": "12.5",
+			"attrs": map[string]any{"tags": []any{"a"}},
+			"data":  []byte{1, 2},
+			"other": "text",
+		},
+		{
+			"id":    int64(2),
+			"name":  nil,
+			"price": nil,
+			"attrs": nil,
+			"data":  nil,
+			"other": int64(3),
+		},
+	}, list)
+
+	rows, err = db.QueryContext(ctx, "SELECT attrs FROM test_scan_map WHERE id = 1")
+	assert.NoError(t, err)
+	defer rows.Close()
+	_, err = daox.ScanMap(rows, daox.WithColumnConverter("attrs", func(val any) (any, error) {
+		return daox.JSONConverter("{")
+	}))
+	assert.ErrorContains(t, err, "convert column attrs")
+}
@@ -0,0 +1,45 @@
+package daox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fengjx/daox/engine"
+)
+
+// SlowQueryHook 慢查询中间件，执行耗时超过阈值时回调 sink
+type SlowQueryHook struct {
+	threshold time.Duration
+	sink      engine.AfterHandler
+}
+
+// NewSlowQueryHook 创建慢查询中间件
+// threshold 慢查询阈值，sink 慢查询处理回调，如打印日志、上报告警等，为 nil 时使用 LogSlowQuery 打印日志
+func NewSlowQueryHook(threshold time.Duration, sink engine.AfterHandler) *SlowQueryHook {
+	if sink == nil {
+		sink = LogSlowQuery
+	}
+	return &SlowQueryHook{
+		threshold: threshold,
+		sink:      sink,
+	}
+}
+
+// Before 执行前
+func (h *SlowQueryHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	return nil
+}
+
+// After 执行后
+func (h *SlowQueryHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+	if er.Duration < h.threshold {
+		return
+	}
+	h.sink(ctx, ec, er)
+}
+
+// LogSlowQuery 使用标准库 log 打印慢查询日志
+func LogSlowQuery(_ context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+	log.Printf("[daox] slow query, duration: %s, sql: %s, args: %v, err: %v", er.Duration, ec.SQL, ec.Args, er.Err)
+}
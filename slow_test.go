@@ -0,0 +1,48 @@
+package daox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/engine"
+)
+
+func TestSlowQueryHook(t *testing.T) {
+	tableName := "demo_info_slow"
+	before(t, tableName)
+	var slowSQL []string
+	sink := func(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+		slowSQL = append(slowSQL, ec.SQL)
+	}
+	dao := daox.NewDao[*DemoInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(newDb()),
+		daox.WithHooks(daox.NewSlowQueryHook(time.Hour, sink)),
+	)
+	info := &DemoInfo{}
+	_, err := dao.GetByID(1, info)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(slowSQL))
+
+	dao = daox.NewDao[*DemoInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(newDb()),
+		daox.WithHooks(daox.NewSlowQueryHook(0, sink)),
+	)
+	_, err = dao.GetByID(1, info)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(slowSQL))
+
+	// sink 为 nil 时打印日志
+	dao = daox.NewDao[*DemoInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(newDb()),
+		daox.WithHooks(daox.NewSlowQueryHook(0, nil)),
+	)
+	_, err = dao.GetByID(1, info)
+	assert.NoError(t, err)
+}
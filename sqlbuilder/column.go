@@ -130,7 +130,7 @@ func (c Column) Express() string {
 		sb.WriteByte('.')
 	}
 	sb.WriteByte('`')
-	sb.WriteString(strings.TrimSpace(c.name))
+	sb.WriteString(escapeIdent(c.name))
 	sb.WriteByte('`')
 	sb.WriteString(c.op.Text)
 	if c.HasInSQL() {
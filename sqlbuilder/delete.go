@@ -17,6 +17,7 @@ type Deleter struct {
 	tableName string
 	where     ConditionBuilder
 	limit     *int
+	scopes    []Scope
 }
 
 // NewDeleter
@@ -40,6 +41,29 @@ func (d *Deleter) Where(where ConditionBuilder) *Deleter {
 	return d
 }
 
+// Scopes 添加执行时根据上下文追加的 where 条件
+func (d *Deleter) Scopes(scopes ...Scope) *Deleter {
+	d.scopes = append(d.scopes, scopes...)
+	return d
+}
+
+// useScopes 执行前合并 scope 条件，返回的函数用于还原 where 条件
+func (d *Deleter) useScopes(ctx context.Context) (func(), error) {
+	origin := d.where
+	// 必须先校验原始的 where 条件，避免只有 scope 条件时误删数据
+	if origin == nil || len(origin.getPredicates()) == 0 {
+		return nil, ErrDeleteMissWhere
+	}
+	where, err := applyScopes(ctx, origin, d.scopes)
+	if err != nil {
+		return nil, err
+	}
+	d.where = where
+	return func() {
+		d.where = origin
+	}, nil
+}
+
 // Limit 限制删除数量
 func (d *Deleter) Limit(limit int) *Deleter {
 	d.limit = &limit
@@ -89,6 +113,11 @@ func (d *Deleter) ExecContext(ctx context.Context) (int64, error) {
 	if d.execer == nil {
 		return 0, ErrExecerNotSet
 	}
+	restore, err := d.useScopes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer restore()
 	execSQL, args, err := d.SQLArgs()
 	if err != nil {
 		return 0, err
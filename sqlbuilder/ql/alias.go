@@ -19,3 +19,8 @@ var Col = sqlbuilder.Col
 
 // F alias for sqlbuilder.F
 var F = sqlbuilder.F
+
+// Typed alias for sqlbuilder.TCol
+func Typed[T any](name string) sqlbuilder.TypedCol[T] {
+	return sqlbuilder.TCol[T](name)
+}
@@ -0,0 +1,62 @@
+package sqlbuilder
+
+import (
+	"context"
+	"strings"
+)
+
+// Scope 根据上下文生成附加的 where 条件，执行时与 where 条件使用 AND 合并
+// 一般用于多租户、软删除等需要统一追加条件的场景，返回 nil 表示不追加条件
+type Scope func(ctx context.Context) (ConditionBuilder, error)
+
+// applyScopes 合并 where 条件和 scope 条件
+// 原始 where 条件在前，scope 条件追加在后面，保证 name 风格的参数顺序不变
+func applyScopes(ctx context.Context, where ConditionBuilder, scopes []Scope) (ConditionBuilder, error) {
+	if len(scopes) == 0 {
+		return where, nil
+	}
+	conds := []ConditionBuilder{where}
+	for _, scope := range scopes {
+		cond, err := scope(ctx)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	merged := &Condition{}
+	for _, cond := range conds {
+		if cond == nil || len(cond.getPredicates()) == 0 {
+			continue
+		}
+		merged.predicates = append(merged.predicates, groupPredicates(cond.getPredicates()))
+	}
+	if len(merged.predicates) == 0 {
+		return where, nil
+	}
+	return merged, nil
+}
+
+// groupPredicates 将多个条件合并成一个使用括号包裹的条件
+func groupPredicates(predicates []Predicate) Predicate {
+	if len(predicates) == 1 {
+		p := predicates[0]
+		p.Op = OpAnd
+		return p
+	}
+	group := Predicate{Op: OpAnd}
+	sb := strings.Builder{}
+	sb.WriteByte('(')
+	for i, p := range predicates {
+		if i > 0 {
+			sb.WriteString(p.Op.Text)
+		}
+		sb.WriteString(p.Express)
+		group.Args = append(group.Args, p.Args...)
+		if p.HasInSQL {
+			group.HasInSQL = true
+		}
+	}
+	sb.WriteByte(')')
+	group.Express = sb.String()
+	return group
+}
@@ -0,0 +1,87 @@
+package sqlbuilder_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox/sqlbuilder"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+type tenantKey struct{}
+
+var errNoTenant = errors.New("no tenant")
+
+func tenantScope(ctx context.Context) (sqlbuilder.ConditionBuilder, error) {
+	tenantID, ok := ctx.Value(tenantKey{}).(int64)
+	if !ok {
+		return nil, errNoTenant
+	}
+	return ql.C(ql.Col("tenant_id").EQ(tenantID)), nil
+}
+
+func TestScopes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	dbx := sqlx.NewDb(db, "mysql")
+	ctx := context.WithValue(context.Background(), tenantKey{}, int64(10))
+
+	mock.ExpectQuery("SELECT `id` FROM `user` WHERE \\(`age` > \\? OR `sex` = \\?\\) AND `tenant_id` = \\?;").
+		WithArgs(18, 1, int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	var ids []int64
+	selector := sqlbuilder.NewSelector("user").Columns("id").Queryer(dbx).
+		Where(ql.C().And(ql.Col("age").GT(18)).Or(ql.Col("sex").EQ(1))).
+		Scopes(tenantScope)
+	err = selector.SelectContext(ctx, &ids)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, ids)
+	// 生成 sql 时同样合并 scope 条件，不会修改原始的 where 条件
+	_, err = selector.SQL()
+	assert.ErrorIs(t, err, errNoTenant)
+	querySQL, args, err := selector.SQLArgsContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT `id` FROM `user` WHERE (`age` > ? OR `sex` = ?) AND `tenant_id` = ?;", querySQL)
+	assert.Equal(t, []any{18, 1, int64(10)}, args)
+	querySQL, err = selector.CountSQLContext(context.WithValue(context.Background(), tenantKey{}, int64(11)))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM `user` WHERE (`age` > ? OR `sex` = ?) AND `tenant_id` = ?;", querySQL)
+
+	mock.ExpectExec("UPDATE `user` SET `name` = \\? WHERE `id` = \\? AND `tenant_id` = \\?;").
+		WithArgs("fengjx", 1, int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	affected, err := sqlbuilder.NewUpdater("user").Execer(dbx).
+		Columns("name").
+		Where(ql.SC().And("`id` = :id")).
+		Scopes(tenantScope).
+		NamedExecContext(ctx, map[string]any{"id": 1, "name": "fengjx"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	mock.ExpectExec("DELETE FROM `user` WHERE `id` IN \\(\\?, \\?\\) AND `tenant_id` = \\?;").
+		WithArgs(1, 2, int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	affected, err = sqlbuilder.NewDeleter("user").Execer(dbx).
+		Where(ql.C(ql.Col("id").In(1, 2))).
+		Scopes(tenantScope).
+		ExecContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	// 只有 scope 条件时不允许删除
+	_, err = sqlbuilder.NewDeleter("user").Execer(dbx).Scopes(tenantScope).ExecContext(ctx)
+	assert.Equal(t, sqlbuilder.ErrDeleteMissWhere, err)
+
+	// scope 返回异常
+	err = selector.SelectContext(context.Background(), &ids)
+	assert.Equal(t, errNoTenant, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"maps"
+	"slices"
 	"strconv"
 	"time"
 
@@ -99,6 +101,8 @@ type Selector struct {
 	offset      *int64
 	isForUpdate bool
 	ifNullVals  map[string]string
+	scopes      []Scope
+	namedArgs   any
 }
 
 // NewSelector 创建一个selector
@@ -109,6 +113,28 @@ func NewSelector(tableName string) *Selector {
 	return selector
 }
 
+// Clone 复制查询构造器，修改副本的分页、排序等设置不会影响原构造器
+func (s *Selector) Clone() *Selector {
+	return &Selector{
+		queryer:     s.queryer,
+		tableName:   s.tableName,
+		tableAlias:  s.tableAlias,
+		joins:       slices.Clone(s.joins),
+		queryString: s.queryString,
+		distinct:    s.distinct,
+		columns:     slices.Clone(s.columns),
+		where:       s.where,
+		orderBy:     slices.Clone(s.orderBy),
+		groupBy:     slices.Clone(s.groupBy),
+		limit:       s.limit,
+		offset:      s.offset,
+		isForUpdate: s.isForUpdate,
+		ifNullVals:  maps.Clone(s.ifNullVals),
+		scopes:      slices.Clone(s.scopes),
+		namedArgs:   s.namedArgs,
+	}
+}
+
 // Queryer 设置查询器
 func (s *Selector) Queryer(queryer engine.Queryer) *Selector {
 	s.queryer = queryer
@@ -219,6 +245,24 @@ func (s *Selector) Where(where ConditionBuilder) *Selector {
 	return s
 }
 
+// Scopes 添加执行时根据上下文追加的 where 条件
+func (s *Selector) Scopes(scopes ...Scope) *Selector {
+	s.scopes = append(s.scopes, scopes...)
+	return s
+}
+
+// scopedWhere 合并 where 条件和 scope 条件，不会修改 selector 本身
+func (s *Selector) scopedWhere(ctx context.Context) (ConditionBuilder, error) {
+	return applyScopes(ctx, s.where, s.scopes)
+}
+
+// NamedArgs where 条件使用 name 风格时，通过 data 填充参数
+// name 风格的参数和 ? 参数按照在 sql 中出现的顺序绑定
+func (s *Selector) NamedArgs(data any) *Selector {
+	s.namedArgs = data
+	return s
+}
+
 // ForUpdate select for update
 func (s *Selector) ForUpdate(isForUpdate bool) *Selector {
 	s.isForUpdate = isForUpdate
@@ -251,7 +295,21 @@ func (s *Selector) Offset(offset int64) *Selector {
 }
 
 // SQL 输出sql语句
+// 设置了 Scopes 时使用 context.Background() 生成 scope 条件，scope 依赖上下文时（如多租户）使用 SQLContext
 func (s *Selector) SQL() (string, error) {
+	return s.SQLContext(context.Background())
+}
+
+// SQLContext 根据上下文合并 scope 条件后输出sql语句
+func (s *Selector) SQLContext(ctx context.Context) (string, error) {
+	where, err := s.scopedWhere(ctx)
+	if err != nil {
+		return "", err
+	}
+	return s.buildSQL(where), nil
+}
+
+func (s *Selector) buildSQL(where ConditionBuilder) string {
 	s.preSQL()
 	s.writeString("SELECT ")
 	if s.queryString != "" {
@@ -298,7 +356,7 @@ func (s *Selector) SQL() (string, error) {
 			s.writeString(j.on)
 		}
 	}
-	s.whereSQL(s.where)
+	s.whereSQL(where)
 
 	if len(s.groupBy) > 0 {
 		s.writeString(" GROUP BY ")
@@ -342,11 +400,24 @@ func (s *Selector) SQL() (string, error) {
 		s.writeString(" FOR UPDATE ")
 	}
 	s.end()
-	return s.sb.String(), nil
+	return s.sb.String()
 }
 
-// CountSQL 构造 count 查询 sql
+// CountSQL 构造 count 查询 sql，scope 条件的处理与 SQL 一致
 func (s *Selector) CountSQL() (string, error) {
+	return s.CountSQLContext(context.Background())
+}
+
+// CountSQLContext 根据上下文合并 scope 条件后构造 count 查询 sql
+func (s *Selector) CountSQLContext(ctx context.Context) (string, error) {
+	where, err := s.scopedWhere(ctx)
+	if err != nil {
+		return "", err
+	}
+	return s.buildCountSQL(where), nil
+}
+
+func (s *Selector) buildCountSQL(where ConditionBuilder) string {
 	s.preSQL()
 	s.writeString("SELECT COUNT(*)")
 	s.writeString(" FROM ")
@@ -370,7 +441,7 @@ func (s *Selector) CountSQL() (string, error) {
 			s.writeString(j.on)
 		}
 	}
-	s.whereSQL(s.where)
+	s.whereSQL(where)
 
 	if len(s.groupBy) > 0 {
 		s.writeString(" GROUP BY ")
@@ -383,28 +454,54 @@ func (s *Selector) CountSQL() (string, error) {
 		}
 	}
 	s.end()
-	return s.sb.String(), nil
+	return s.sb.String()
 }
 
-// SQLArgs 构造 sql 并返回对应参数
+// SQLArgs 构造 sql 并返回对应参数，scope 条件的处理与 SQL 一致
 func (s *Selector) SQLArgs() (string, []any, error) {
-	querySQL, err := s.SQL()
+	return s.SQLArgsContext(context.Background())
+}
+
+// SQLArgsContext 根据上下文合并 scope 条件后构造 sql 并返回对应参数
+func (s *Selector) SQLArgsContext(ctx context.Context) (string, []any, error) {
+	where, err := s.scopedWhere(ctx)
 	if err != nil {
 		return "", nil, err
 	}
-	args, hasInSQL := s.whereArgs(s.where)
-	if !hasInSQL {
-		return querySQL, args, err
-	}
-	return sqlx.In(querySQL, args...)
+	return s.bindArgs(s.buildSQL(where), where)
 }
 
-// CountSQLArgs 构造 count 查询 sql 并返回对应参数
+// CountSQLArgs 构造 count 查询 sql 并返回对应参数，scope 条件的处理与 SQL 一致
 func (s *Selector) CountSQLArgs() (string, []any, error) {
-	querySQL, err := s.CountSQL()
-	args, hasInSQL := s.whereArgs(s.where)
+	return s.CountSQLArgsContext(context.Background())
+}
+
+// CountSQLArgsContext 根据上下文合并 scope 条件后构造 count 查询 sql 并返回对应参数
+func (s *Selector) CountSQLArgsContext(ctx context.Context) (string, []any, error) {
+	where, err := s.scopedWhere(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.bindArgs(s.buildCountSQL(where), where)
+}
+
+// bindArgs 填充 where 条件参数
+func (s *Selector) bindArgs(querySQL string, where ConditionBuilder) (string, []any, error) {
+	args, hasInSQL := s.whereArgs(where)
+	if s.namedArgs != nil {
+		var (
+			namedArgs []any
+			err       error
+		)
+		placeholders := namedPlaceholders(querySQL)
+		querySQL, namedArgs, err = sqlx.Named(querySQL, s.namedArgs)
+		if err != nil {
+			return "", nil, err
+		}
+		args = mergeArgs(placeholders, namedArgs, args)
+	}
 	if !hasInSQL {
-		return querySQL, args, err
+		return querySQL, args, nil
 	}
 	return sqlx.In(querySQL, args...)
 }
@@ -419,7 +516,7 @@ func (s *Selector) SelectContext(ctx context.Context, dest any) error {
 	if s.queryer == nil {
 		return ErrQueryerNotSet
 	}
-	querySQL, args, err := s.SQLArgs()
+	querySQL, args, err := s.SQLArgsContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -438,6 +535,36 @@ func (s *Selector) SelectContext(ctx context.Context, dest any) error {
 	return nil
 }
 
+// StreamContext 流式查询，通过 fn 逐行处理结果
+// queryer 实现了 engine.RowsQueryer 时使用流式查询，执行过程会经过 hook
+func (s *Selector) StreamContext(ctx context.Context, fn engine.RowsHandler) error {
+	if s.queryer == nil {
+		return ErrQueryerNotSet
+	}
+	querySQL, args, err := s.SQLArgsContext(ctx)
+	if err != nil {
+		return err
+	}
+	ec := &engine.ExecutorContext{
+		Type:      engine.SELECT,
+		SQL:       querySQL,
+		TableName: s.tableName,
+		Start:     time.Now(),
+		Args:      args,
+	}
+	ctx = engine.SetExecutorContext(ctx, ec)
+	if rq, ok := s.queryer.(engine.RowsQueryer); ok {
+		return rq.StreamContext(ctx, fn, querySQL, args...)
+	}
+	rows, err := s.queryer.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	_, err = fn(rows)
+	return err
+}
+
 // Get 查询单条数据
 func (s *Selector) Get(dest any) (exist bool, err error) {
 	return s.GetContext(context.Background(), dest)
@@ -448,7 +575,7 @@ func (s *Selector) GetContext(ctx context.Context, dest any) (exist bool, err er
 	if s.queryer == nil {
 		return false, ErrQueryerNotSet
 	}
-	querySQL, args, err := s.SQLArgs()
+	querySQL, args, err := s.SQLArgsContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -477,7 +604,10 @@ func (s *Selector) GetCount() (int64, error) {
 
 // GetCountContext 查询总记录数
 func (s *Selector) GetCountContext(ctx context.Context) (int64, error) {
-	querySQL, args, err := s.CountSQLArgs()
+	if s.queryer == nil {
+		return 0, ErrQueryerNotSet
+	}
+	querySQL, args, err := s.CountSQLArgsContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/jmoiron/sqlx/reflectx"
 
@@ -38,14 +39,28 @@ func GetColumnsByModel(mapper *reflectx.Mapper, model any, omitColumns ...string
 }
 
 // GetColumnsByType 通过字段 tag 解析数据库字段
+// 带有 rel tag 的关联字段不是数据库字段，会被忽略，匿名嵌入结构体的字段会展开
 func GetColumnsByType(mapper *reflectx.Mapper, typ reflect.Type, omitColumns ...string) []string {
 	structMap := mapper.TypeMap(typ)
-	columns := make([]string, 0)
-	for _, fieldInfo := range structMap.Tree.Children {
-		if fieldInfo == nil || fieldInfo.Name == "" || utils.ContainsString(omitColumns, fieldInfo.Name) {
+	return appendColumns(make([]string, 0), structMap.Tree.Children, omitColumns)
+}
+
+func appendColumns(columns []string, fields []*reflectx.FieldInfo, omitColumns []string) []string {
+	for _, fieldInfo := range fields {
+		if fieldInfo == nil || fieldInfo.Name == "" {
+			continue
+		}
+		if _, ok := fieldInfo.Field.Tag.Lookup("rel"); ok {
+			continue
+		}
+		if fieldInfo.Embedded && len(fieldInfo.Children) > 0 {
+			columns = appendColumns(columns, fieldInfo.Children, omitColumns)
 			continue
 		}
-		columns = append(columns, fieldInfo.Name)
+		if utils.ContainsString(omitColumns, fieldInfo.Path) {
+			continue
+		}
+		columns = append(columns, fieldInfo.Path)
 	}
 	return columns
 }
@@ -100,17 +115,27 @@ func (b *sqlBuilder) writeByte(c byte) {
 
 func (b *sqlBuilder) quote(val string) {
 	b.writeByte('`')
-	b.writeString(strings.TrimSpace(val))
+	b.writeString(escapeIdent(val))
 	b.writeByte('`')
 }
 
+// Quote 给字段名加上反引号，eg: name -> `name`
+func Quote(name string) string {
+	return "`" + escapeIdent(name) + "`"
+}
+
+// escapeIdent 转义标识符中的反引号，避免字段名中的反引号闭合标识符造成注入
+func escapeIdent(name string) string {
+	return strings.ReplaceAll(strings.TrimSpace(name), "`", "``")
+}
+
 func (b *sqlBuilder) col(col column) {
 	if col.alias != "" {
 		b.writeString(col.alias)
 		b.writeByte('.')
 	}
 	b.writeByte('`')
-	b.writeString(strings.TrimSpace(col.name))
+	b.writeString(escapeIdent(col.name))
 	b.writeByte('`')
 }
 
@@ -162,6 +187,50 @@ func (b *sqlBuilder) whereArgs(where ConditionBuilder) (args []any, hasInSQL boo
 	return
 }
 
+// namedPlaceholders 按出现顺序返回 sql 中的占位符是否为 :name 形式的命名参数，解析规则与 sqlx.Named 一致
+func namedPlaceholders(query string) []bool {
+	var placeholders []bool
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '?':
+			placeholders = append(placeholders, false)
+		case ':':
+			if i+1 < len(query) && query[i+1] == ':' {
+				// :: 转义成 :
+				i++
+				continue
+			}
+			if i+1 < len(query) && isNameChar(rune(query[i+1])) {
+				placeholders = append(placeholders, true)
+				for i+1 < len(query) && isNameChar(rune(query[i+1])) {
+					i++
+				}
+			}
+		}
+	}
+	return placeholders
+}
+
+func isNameChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+// mergeArgs 按占位符出现的顺序合并命名参数和 ? 参数
+func mergeArgs(placeholders []bool, namedArgs, args []any) []any {
+	merged := make([]any, 0, len(namedArgs)+len(args))
+	for _, named := range placeholders {
+		if named && len(namedArgs) > 0 {
+			merged = append(merged, namedArgs[0])
+			namedArgs = namedArgs[1:]
+		} else if !named && len(args) > 0 {
+			merged = append(merged, args[0])
+			args = args[1:]
+		}
+	}
+	merged = append(merged, namedArgs...)
+	return append(merged, args...)
+}
+
 // setFields 字段赋值语句
 func (b *sqlBuilder) setFields(fields []Field) {
 	n := len(fields)
@@ -282,6 +282,33 @@ func TestSelect(t *testing.T) {
 			wantSQL:  "SELECT b.`id`, b.`title`, u.`id`, u.`username` FROM `blog` AS `u` LEFT JOIN `user` AS `b` ON b.uid = u.id WHERE u.`id` = ?;",
 			wantArgs: []interface{}{1000},
 		},
+		{
+			name: "select escape backtick",
+			selector: sqlbuilder.New("user").Select().
+				Columns("id`, `password").
+				Where(ql.C(ql.Col("id` = 1 OR `1").EQ(1))).
+				OrderBy(ql.Desc("ctime`; DROP")),
+			wantSQL:  "SELECT `id``, ``password` FROM `user` WHERE `id`` = 1 OR ``1` = ? ORDER BY `ctime``; DROP` DESC;",
+			wantArgs: []interface{}{1},
+		},
+		{
+			name: "select named args",
+			selector: sqlbuilder.New("user").Select().
+				Columns("id", "username").
+				Where(ql.SC().And("`age` > :age").And("`sex` = ?", 1)).
+				NamedArgs(map[string]any{"age": 18}),
+			wantSQL:  "SELECT `id`, `username` FROM `user` WHERE `age` > ? AND `sex` = ?;",
+			wantArgs: []any{18, 1},
+		},
+		{
+			name: "select named args after positional args",
+			selector: sqlbuilder.New("user").Select().
+				Columns("id", "username").
+				Where(ql.SC().And("`sex` = ?", 1).And("`age` > :age").And("`uid` > ?", 2).And("`name` = :name")).
+				NamedArgs(map[string]any{"age": 18, "name": "u1"}),
+			wantSQL:  "SELECT `id`, `username` FROM `user` WHERE `sex` = ? AND `age` > ? AND `uid` > ? AND `name` = ?;",
+			wantArgs: []any{1, 18, 2, "u1"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,129 @@
+package sqlbuilder
+
+import "reflect"
+
+// TypedCol 泛型表字段，条件参数只能是 T 类型，可以在编译期检查参数类型
+// eg: TCol[int64]("uid").EQ(1)
+type TypedCol[T any] struct {
+	name  string
+	alias string
+}
+
+// TCol 创建泛型表字段
+func TCol[T any](name string) TypedCol[T] {
+	return TypedCol[T]{name: name}
+}
+
+// Name 字段名
+func (c TypedCol[T]) Name() string {
+	return c.name
+}
+
+// String 字段名
+func (c TypedCol[T]) String() string {
+	return c.name
+}
+
+// TableAlias 表别名
+func (c TypedCol[T]) TableAlias() string {
+	return c.alias
+}
+
+// GoType 字段参数的 go 类型
+func (c TypedCol[T]) GoType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// Alias 设置表别名，eg: u.`uid`
+func (c TypedCol[T]) Alias(alias string) TypedCol[T] {
+	c.alias = alias
+	return c
+}
+
+// Col 转换成 Column
+func (c TypedCol[T]) Col() Column {
+	return Col(c.name).Alias(c.alias)
+}
+
+// EQ =
+func (c TypedCol[T]) EQ(val T) Column {
+	return c.Col().EQ(val)
+}
+
+// NotEQ !=
+func (c TypedCol[T]) NotEQ(val T) Column {
+	return c.Col().NotEQ(val)
+}
+
+// LT <
+func (c TypedCol[T]) LT(val T) Column {
+	return c.Col().LT(val)
+}
+
+// LTEQ <=
+func (c TypedCol[T]) LTEQ(val T) Column {
+	return c.Col().LTEQ(val)
+}
+
+// GT >
+func (c TypedCol[T]) GT(val T) Column {
+	return c.Col().GT(val)
+}
+
+// GTEQ >=
+func (c TypedCol[T]) GTEQ(val T) Column {
+	return c.Col().GTEQ(val)
+}
+
+// Like -> LIKE %XXX
+func (c TypedCol[T]) Like(val T) Column {
+	return c.Col().Like(val)
+}
+
+// NotLike -> NOT LIKE %XXX
+func (c TypedCol[T]) NotLike(val T) Column {
+	return c.Col().NotLike(val)
+}
+
+// In -> in ()
+func (c TypedCol[T]) In(vals ...T) Column {
+	return c.Col().In(toAnys(vals)...)
+}
+
+// NotIn -> not in ()
+func (c TypedCol[T]) NotIn(vals ...T) Column {
+	return c.Col().NotIn(toAnys(vals)...)
+}
+
+// IsNull -> IS NULL
+func (c TypedCol[T]) IsNull() Column {
+	return c.Col().IsNull()
+}
+
+// IsNotNull -> IS NOT NULL
+func (c TypedCol[T]) IsNotNull() Column {
+	return c.Col().IsNotNull()
+}
+
+// Asc 升序
+func (c TypedCol[T]) Asc() OrderBy {
+	return Asc(c.name).Alias(c.alias)
+}
+
+// Desc 降序
+func (c TypedCol[T]) Desc() OrderBy {
+	return Desc(c.name).Alias(c.alias)
+}
+
+// Set 更新字段值
+func (c TypedCol[T]) Set(val T) Field {
+	return F(c.name).Val(val)
+}
+
+func toAnys[T any](vals []T) []any {
+	args := make([]any, len(vals))
+	for i, val := range vals {
+		args[i] = val
+	}
+	return args
+}
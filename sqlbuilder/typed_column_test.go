@@ -0,0 +1,58 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox/sqlbuilder"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+func TestTypedCol(t *testing.T) {
+	uid := ql.Typed[int64]("uid")
+	name := ql.Typed[string]("name")
+	assert.Equal(t, "uid", uid.Name())
+	assert.Equal(t, "uid", uid.String())
+
+	testCases := []struct {
+		name     string
+		selector *sqlbuilder.Selector
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name: "typed where",
+			selector: sqlbuilder.New("user").Select().
+				Where(ql.C(uid.EQ(1), name.Like("%jo%"), uid.In(1, 2, 3))).
+				OrderBy(uid.Desc()),
+			wantSQL:  "SELECT * FROM `user` WHERE `uid` = ? AND `name` LIKE ? AND `uid` IN (?, ?, ?) ORDER BY `uid` DESC;",
+			wantArgs: []any{int64(1), "%jo%", int64(1), int64(2), int64(3)},
+		},
+		{
+			name: "typed where alias",
+			selector: sqlbuilder.New("user").Select().As("u").
+				Columns("id").
+				Where(ql.C(uid.Alias("u").GTEQ(10), name.Alias("u").IsNull())).
+				OrderBy(name.Alias("u").Asc()),
+			wantSQL:  "SELECT u.`id` FROM `user` AS `u` WHERE u.`uid` >= ? AND u.`name` IS NULL ORDER BY u.`name` ASC;",
+			wantArgs: []any{int64(10)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args, err := tc.selector.SQLArgs()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantSQL, sql)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+
+	sql, args, err := sqlbuilder.New("user").Update().
+		Fields(name.Set("fengjx")).
+		Where(ql.C(uid.NotIn(1, 2))).
+		SQLArgs()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE `user` SET `name` = ? WHERE `uid` NOT IN (?, ?);", sql)
+	assert.Equal(t, []any{"fengjx", int64(1), int64(2)}, args)
+}
@@ -16,6 +16,7 @@ type Updater struct {
 	tableName string
 	fields    []Field
 	where     ConditionBuilder
+	scopes    []Scope
 }
 
 // NewUpdater 创建一个 update 语句构造器
@@ -69,6 +70,29 @@ func (u *Updater) Where(where ConditionBuilder) *Updater {
 	return u
 }
 
+// Scopes 添加执行时根据上下文追加的 where 条件
+func (u *Updater) Scopes(scopes ...Scope) *Updater {
+	u.scopes = append(u.scopes, scopes...)
+	return u
+}
+
+// useScopes 执行前合并 scope 条件，返回的函数用于还原 where 条件
+func (u *Updater) useScopes(ctx context.Context) (func(), error) {
+	origin := u.where
+	// 必须先校验原始的 where 条件，避免只有 scope 条件时误更新数据
+	if origin != nil && len(origin.getPredicates()) == 0 {
+		return nil, ErrUpdateMissWhere
+	}
+	where, err := applyScopes(ctx, origin, u.scopes)
+	if err != nil {
+		return nil, err
+	}
+	u.where = where
+	return func() {
+		u.where = origin
+	}, nil
+}
+
 // SQL 输出sql语句
 func (u *Updater) SQL() (string, error) {
 	if len(u.fields) == 0 {
@@ -136,6 +160,11 @@ func (u *Updater) ExecContext(ctx context.Context) (int64, error) {
 	if u.execer == nil {
 		return 0, ErrExecerNotSet
 	}
+	restore, err := u.useScopes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer restore()
 	execSQL, args, err := u.SQLArgs()
 	if err != nil {
 		return 0, err
@@ -167,6 +196,9 @@ func (u *Updater) NamedExecContext(ctx context.Context, data any) (int64, error)
 	if u.execer == nil {
 		return 0, ErrExecerNotSet
 	}
+	if len(u.scopes) > 0 {
+		return u.namedExecWithScopes(ctx, data)
+	}
 	execSQL, err := u.NameSQL()
 	if err != nil {
 		return 0, err
@@ -185,3 +217,42 @@ func (u *Updater) NamedExecContext(ctx context.Context, data any) (int64, error)
 	}
 	return result.RowsAffected()
 }
+
+// namedExecWithScopes 合并 scope 条件后执行 name 风格的更新语句
+// scope 条件使用数组参数，所以先将 name 风格的 sql 转换成数组参数风格再执行
+func (u *Updater) namedExecWithScopes(ctx context.Context, data any) (int64, error) {
+	restore, err := u.useScopes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer restore()
+	nameSQL, err := u.NameSQL()
+	if err != nil {
+		return 0, err
+	}
+	execSQL, args, err := sqlx.Named(nameSQL, data)
+	if err != nil {
+		return 0, err
+	}
+	wargs, hasInSQL := u.whereArgs(u.where)
+	args = append(args, wargs...)
+	if hasInSQL {
+		execSQL, args, err = sqlx.In(execSQL, args...)
+		if err != nil {
+			return 0, err
+		}
+	}
+	ec := &engine.ExecutorContext{
+		Type:      engine.UPDATE,
+		SQL:       execSQL,
+		TableName: u.tableName,
+		Start:     time.Now(),
+		Args:      args,
+	}
+	ctx = engine.SetExecutorContext(ctx, ec)
+	result, err := u.execer.ExecContext(ctx, execSQL, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
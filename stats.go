@@ -0,0 +1,197 @@
+package daox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fengjx/daox/engine"
+)
+
+const (
+	defaultStatsSampleSize    = 1000
+	defaultStatsMaxStatements = 1000
+)
+
+// StatementStats sql 语句统计信息，耗时单位为纳秒
+type StatementStats struct {
+	Fingerprint string         `json:"fingerprint"` // sql 指纹
+	Type        engine.SQLType `json:"type"`        // sql 类型
+	TableName   string         `json:"table_name"`  // 表名
+	Count       int64          `json:"count"`       // 执行次数
+	Errors      int64          `json:"errors"`      // 异常次数
+	ErrorRate   float64        `json:"error_rate"`  // 异常率
+	Total       time.Duration  `json:"total"`       // 总耗时
+	Avg         time.Duration  `json:"avg"`         // 平均耗时
+	Max         time.Duration  `json:"max"`         // 最大耗时
+	P50         time.Duration  `json:"p50"`         // 50 分位耗时
+	P95         time.Duration  `json:"p95"`         // 95 分位耗时
+	P99         time.Duration  `json:"p99"`         // 99 分位耗时
+}
+
+// statementStats 单条 sql 指纹的统计数据
+type statementStats struct {
+	typ       engine.SQLType
+	tableName string
+	count     int64
+	errors    int64
+	total     time.Duration
+	max       time.Duration
+	samples   []time.Duration // 最近的耗时采样，环形写入
+	next      int
+}
+
+func (s *statementStats) add(d time.Duration, err error, sampleSize int) {
+	s.count++
+	if err != nil {
+		s.errors++
+	}
+	s.total += d
+	if d > s.max {
+		s.max = d
+	}
+	if len(s.samples) < sampleSize {
+		s.samples = append(s.samples, d)
+		return
+	}
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % sampleSize
+}
+
+func (s *statementStats) snapshot(fingerprint string) StatementStats {
+	samples := make([]time.Duration, len(s.samples))
+	copy(samples, s.samples)
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	stats := StatementStats{
+		Fingerprint: fingerprint,
+		Type:        s.typ,
+		TableName:   s.tableName,
+		Count:       s.count,
+		Errors:      s.errors,
+		Total:       s.total,
+		Max:         s.max,
+		P50:         percentile(samples, 0.50),
+		P95:         percentile(samples, 0.95),
+		P99:         percentile(samples, 0.99),
+	}
+	if s.count > 0 {
+		stats.ErrorRate = float64(s.errors) / float64(s.count)
+		stats.Avg = s.total / time.Duration(s.count)
+	}
+	return stats
+}
+
+// percentile 计算分位值，samples 需要是有序的
+func percentile(samples []time.Duration, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	idx := int(float64(len(samples))*p+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(samples) {
+		idx = len(samples) - 1
+	}
+	return samples[idx]
+}
+
+// StatsOption 统计配置
+type StatsOption func(*StatsCollector)
+
+// WithStatsSampleSize 每条 sql 指纹保留的耗时采样数，用于计算分位值，默认 1000，小于等于 0 时使用默认值
+func WithStatsSampleSize(size int) StatsOption {
+	return func(c *StatsCollector) {
+		if size > 0 {
+			c.sampleSize = size
+		}
+	}
+}
+
+// WithStatsMaxStatements 最多统计的 sql 指纹数量，超出后新的 sql 不再统计，默认 1000
+func WithStatsMaxStatements(max int) StatsOption {
+	return func(c *StatsCollector) {
+		c.maxStatements = max
+	}
+}
+
+// StatsCollector sql 执行统计中间件
+// 按 sql 指纹聚合执行次数、异常率、耗时分位值，类似进程内的 pt-query-digest
+type StatsCollector struct {
+	mu            sync.Mutex
+	sampleSize    int
+	maxStatements int
+	statements    map[string]*statementStats
+}
+
+// NewStatsCollector 创建 sql 执行统计中间件
+func NewStatsCollector(opts ...StatsOption) *StatsCollector {
+	c := &StatsCollector{
+		sampleSize:    defaultStatsSampleSize,
+		maxStatements: defaultStatsMaxStatements,
+		statements:    make(map[string]*statementStats),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Before 执行前
+func (c *StatsCollector) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	return nil
+}
+
+// After 执行后
+func (c *StatsCollector) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+	fingerprint := engine.Fingerprint(ec.SQL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats, ok := c.statements[fingerprint]
+	if !ok {
+		if len(c.statements) >= c.maxStatements {
+			return
+		}
+		stats = &statementStats{
+			typ:       ec.Type,
+			tableName: tableNameOf(ec),
+		}
+		c.statements[fingerprint] = stats
+	}
+	stats.add(er.Duration, er.Err, c.sampleSize)
+}
+
+// Snapshot 返回当前统计数据，按总耗时倒序
+func (c *StatsCollector) Snapshot() []StatementStats {
+	c.mu.Lock()
+	list := make([]StatementStats, 0, len(c.statements))
+	for fingerprint, stats := range c.statements {
+		list = append(list, stats.snapshot(fingerprint))
+	}
+	c.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Total == list[j].Total {
+			return list[i].Fingerprint < list[j].Fingerprint
+		}
+		return list[i].Total > list[j].Total
+	})
+	return list
+}
+
+// Reset 清空统计数据
+func (c *StatsCollector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = make(map[string]*statementStats)
+}
+
+// ServeHTTP 以 json 格式输出统计数据
+func (c *StatsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(c.Snapshot())
+}
@@ -0,0 +1,84 @@
+package daox_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/engine"
+)
+
+func TestStatsCollector(t *testing.T) {
+	tableName := "demo_info_stats"
+	before(t, tableName)
+	collector := daox.NewStatsCollector()
+	dao := daox.NewDao[*DemoInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(newDb()),
+		daox.WithHooks(collector),
+	)
+	for i := 1; i <= 5; i++ {
+		var list []*DemoInfo
+		ids := make([]any, 0, i)
+		for j := 1; j <= i; j++ {
+			ids = append(ids, j)
+		}
+		err := dao.ListByIDs(&list, ids...)
+		assert.NoError(t, err)
+	}
+	_, err := dao.DeleteByColumn(daox.OfKv("not_exist", 1))
+	assert.Error(t, err)
+
+	snapshot := collector.Snapshot()
+	assert.Equal(t, 2, len(snapshot))
+	var query, del daox.StatementStats
+	for _, stats := range snapshot {
+		switch stats.Type {
+		case engine.SELECT:
+			query = stats
+		case engine.DELETE:
+			del = stats
+		}
+	}
+	assert.Equal(t, int64(5), query.Count)
+	assert.Equal(t, int64(0), query.Errors)
+	assert.Equal(t, tableName, query.TableName)
+	assert.True(t, query.P50 > 0)
+	assert.True(t, query.P99 >= query.P50)
+	assert.Equal(t, int64(1), del.Count)
+	assert.Equal(t, float64(1), del.ErrorRate)
+
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, httptest.NewRequest("GET", "/debug/daox/stats", nil))
+	var body []daox.StatementStats
+	err = json.Unmarshal(w.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(body))
+
+	collector.Reset()
+	assert.Equal(t, 0, len(collector.Snapshot()))
+}
+
+func TestStatsSampleSize(t *testing.T) {
+	tableName := "demo_info_stats_sample"
+	before(t, tableName)
+	for _, size := range []int{0, -1, 2} {
+		collector := daox.NewStatsCollector(daox.WithStatsSampleSize(size))
+		dao := daox.NewDao[*DemoInfo](tableName, "id",
+			daox.IsAutoIncrement(),
+			daox.WithDBMaster(newDb()),
+			daox.WithHooks(collector),
+		)
+		for i := 0; i < 3; i++ {
+			_, err := dao.GetByID(1, &DemoInfo{})
+			assert.NoError(t, err)
+		}
+		snapshot := collector.Snapshot()
+		assert.Equal(t, 1, len(snapshot))
+		assert.Equal(t, int64(3), snapshot[0].Count)
+		assert.True(t, snapshot[0].P99 > 0)
+	}
+}
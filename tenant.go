@@ -0,0 +1,142 @@
+package daox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+
+	"github.com/fengjx/daox/sqlbuilder"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+// ErrTenantRequired 开启多租户的 dao 执行时，上下文中缺少租户信息
+var ErrTenantRequired = errors.New("[daox] tenant require in context")
+
+type tenantCtxKey struct{}
+
+type ignoreTenantCtxKey struct{}
+
+// WithTenant 在上下文中设置租户 id
+func WithTenant(ctx context.Context, tenantID any) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID)
+}
+
+// GetTenant 获取上下文中的租户 id
+func GetTenant(ctx context.Context) (any, bool) {
+	tenantID := ctx.Value(tenantCtxKey{})
+	return tenantID, tenantID != nil
+}
+
+// IgnoreTenant 忽略租户隔离，一般用于后台管理、数据迁移等需要跨租户操作的场景
+func IgnoreTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ignoreTenantCtxKey{}, true)
+}
+
+// isIgnoreTenant 是否忽略租户隔离
+func isIgnoreTenant(ctx context.Context) bool {
+	ignore, _ := ctx.Value(ignoreTenantCtxKey{}).(bool)
+	return ignore
+}
+
+// tenantColumn 租户字段，未开启多租户时返回空字符串
+func (d *Dao) tenantColumn() string {
+	if d.options == nil {
+		return ""
+	}
+	return d.options.tenantColumn
+}
+
+// tenantScope 租户查询条件
+func (d *Dao) tenantScope(ctx context.Context) (sqlbuilder.ConditionBuilder, error) {
+	if isIgnoreTenant(ctx) {
+		return nil, nil
+	}
+	tenantID, ok := GetTenant(ctx)
+	if !ok {
+		return nil, ErrTenantRequired
+	}
+	return ql.C(ql.Col(d.tenantColumn()).EQ(tenantID)), nil
+}
+
+// stampTenant 保存数据时设置租户字段的值
+// models 可以是结构体指针、map 或者它们的切片
+func (d *Dao) stampTenant(ctx context.Context, models any) error {
+	col := d.tenantColumn()
+	if col == "" || isIgnoreTenant(ctx) {
+		return nil
+	}
+	tenantID, ok := GetTenant(ctx)
+	if !ok {
+		return ErrTenantRequired
+	}
+	v := reflect.ValueOf(models)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+			break
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			if err := setColumnValue(d.mapper, v.Index(i), col, tenantID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return setColumnValue(d.mapper, v, col, tenantID)
+}
+
+// setColumnValue 给结构体或 map 中的数据库字段赋值
+func setColumnValue(mapper *reflectx.Mapper, v reflect.Value, col string, val any) error {
+	for v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		v = v.Addr()
+	}
+	if v.Kind() == reflect.Map {
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("[daox] unsupported map key type %s", v.Type().Key())
+		}
+		v.SetMapIndex(reflect.ValueOf(col), reflect.ValueOf(val))
+		return nil
+	}
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("[daox] can not set column %s to %s, expected struct pointer", col, v.Type())
+	}
+	field := mapper.FieldByName(v.Elem(), col)
+	if !field.IsValid() || !field.CanSet() {
+		return fmt.Errorf("[daox] column %s not found in %s", col, v.Type())
+	}
+	fv := reflect.ValueOf(val)
+	if !convertible(fv.Type(), field.Type()) {
+		return fmt.Errorf("[daox] can not convert %s to %s for column %s", fv.Type(), field.Type(), col)
+	}
+	field.Set(fv.Convert(field.Type()))
+	return nil
+}
+
+// convertible 只允许相同类型或者数值类型之间的转换，避免 int 通过 Convert 转换成 string 时变成字符
+func convertible(from, to reflect.Type) bool {
+	if from.Kind() == to.Kind() {
+		return from.ConvertibleTo(to)
+	}
+	return isNumberKind(from.Kind()) && isNumberKind(to.Kind())
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package daox_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+const createTenantTableSQL = `
+CREATE TABLE %s (
+  id integer primary key autoincrement,
+  tenant_id integer,
+  name text
+);
+`
+
+type tenantInfo struct {
+	ID       int64  `json:"id"`
+	TenantID int64  `json:"tenant_id"`
+	Name     string `json:"name"`
+}
+
+func (m *tenantInfo) GetID() any {
+	return m.ID
+}
+
+func TestTenant(t *testing.T) {
+	tableName := "tenant_info"
+	after(t, tableName)
+	db := newDb()
+	_, err := db.Exec(fmt.Sprintf(createTenantTableSQL, tableName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer after(t, tableName)
+	dao := daox.NewDao[*tenantInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(db),
+		daox.WithTenantColumn("tenant_id"),
+	)
+	ctx1 := daox.WithTenant(context.Background(), 1)
+	ctx2 := daox.WithTenant(context.Background(), 2)
+
+	// 没有租户信息
+	_, err = dao.SaveContext(context.Background(), &tenantInfo{Name: "none"})
+	assert.Equal(t, daox.ErrTenantRequired, err)
+	_, err = dao.GetByIDContext(context.Background(), 1, &tenantInfo{})
+	assert.Equal(t, daox.ErrTenantRequired, err)
+
+	id1, err := dao.SaveContext(ctx1, &tenantInfo{Name: "t1-a"})
+	assert.NoError(t, err)
+	_, err = dao.BatchSaveContext(ctx1, []*tenantInfo{{Name: "t1-b"}, {Name: "t1-c"}})
+	assert.NoError(t, err)
+	id2, err := dao.SaveContext(ctx2, &tenantInfo{Name: "t2-a", TenantID: 1})
+	assert.NoError(t, err)
+
+	// 保存时使用上下文中的租户
+	info := &tenantInfo{}
+	exist, err := dao.GetByIDContext(ctx2, id2, info)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, int64(2), info.TenantID)
+
+	// 不能查询其他租户的数据
+	exist, err = dao.GetByIDContext(ctx2, id1, &tenantInfo{})
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	var list []*tenantInfo
+	err = dao.Selector().Where(ql.C(ql.Col("name").Like("t%"))).SelectContext(ctx1, &list)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(list))
+	count, err := dao.Selector().GetCountContext(ctx2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// 不能修改、删除其他租户的数据
+	ok, err := dao.UpdateFieldContext(ctx2, id1, map[string]any{"name": "hack", "tenant_id": 2})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = dao.UpdateContext(ctx2, &tenantInfo{ID: id1, TenantID: 2, Name: "hack"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = dao.DeleteByIDContext(ctx2, id1)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// 修改时不会修改租户字段
+	ok, err = dao.UpdateContext(ctx1, &tenantInfo{ID: id1, TenantID: 2, Name: "t1-a-1"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	exist, err = dao.GetByIDContext(ctx1, id1, info)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, int64(1), info.TenantID)
+	assert.Equal(t, "t1-a-1", info.Name)
+
+	// 生成 sql 时同样追加租户条件，没有租户信息时返回错误
+	_, _, err = dao.Selector().SQLArgs()
+	assert.Equal(t, daox.ErrTenantRequired, err)
+	selector := dao.Selector().Where(ql.C(ql.Col("name").EQ("t1-a-1")))
+	querySQL, args, err := selector.SQLArgsContext(ctx1)
+	assert.NoError(t, err)
+	assert.Contains(t, querySQL, "`tenant_id` = ?")
+	assert.Equal(t, []any{"t1-a-1", 1}, args)
+	countSQL, _, err := selector.CountSQLArgsContext(ctx2)
+	assert.NoError(t, err)
+	assert.Contains(t, countSQL, "`tenant_id` = ?")
+	// 合并租户条件不会修改 selector 的 where 条件
+	querySQL, args, err = selector.SQLArgsContext(daox.IgnoreTenant(context.Background()))
+	assert.NoError(t, err)
+	assert.NotContains(t, querySQL, "`tenant_id` = ?")
+	assert.Equal(t, []any{"t1-a-1"}, args)
+
+	// 忽略租户
+	list = nil
+	err = dao.Selector().SelectContext(daox.IgnoreTenant(context.Background()), &list)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(list))
+	ok, err = dao.DeleteByIDContext(ctx1, id1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+type tenantStrInfo struct {
+	ID       int64  `json:"id"`
+	TenantID string `json:"tenant_id"`
+	Name     string `json:"name"`
+}
+
+func (m *tenantStrInfo) GetID() any {
+	return m.ID
+}
+
+func TestTenantConvert(t *testing.T) {
+	tableName := "tenant_str_info"
+	after(t, tableName)
+	db := newDb()
+	_, err := db.Exec(fmt.Sprintf(createTenantTableSQL, tableName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer after(t, tableName)
+	dao := daox.NewDao[*tenantStrInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(db),
+		daox.WithTenantColumn("tenant_id"),
+	)
+	// int 不能转换成 string，否则 65 会保存成 "A"
+	_, err = dao.SaveContext(daox.WithTenant(context.Background(), 65), &tenantStrInfo{Name: "a"})
+	assert.ErrorContains(t, err, "[daox] can not convert int to string")
+	count, err := dao.Selector().GetCountContext(daox.IgnoreTenant(context.Background()))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_, err = dao.SaveContext(daox.WithTenant(context.Background(), "65"), &tenantStrInfo{Name: "a"})
+	assert.NoError(t, err)
+
+	// 数值类型之间可以转换
+	intDao := daox.NewDao[*tenantInfo]("tenant_str_info", "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(db),
+		daox.WithTenantColumn("tenant_id"),
+	)
+	_, err = intDao.SaveContext(daox.WithTenant(context.Background(), int32(66)), &tenantInfo{Name: "b"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,103 @@
+package daox
+
+import (
+	"context"
+
+	"github.com/fengjx/daox/engine"
+)
+
+// span 属性名，参考 opentelemetry 数据库语义约定
+const (
+	AttrDBSystem       = "db.system"
+	AttrDBStatement    = "db.statement"
+	AttrDBOperation    = "db.operation"
+	AttrDBTable        = "db.sql.table"
+	AttrDBRowsAffected = "db.rows_affected"
+	AttrDBQueryRows    = "db.query_rows"
+	AttrError          = "error"
+)
+
+// Attribute span 属性
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Tracer 链路追踪接口，通过适配不同的实现（如 opentelemetry）上报 span
+type Tracer interface {
+	// Start 创建 span
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span 链路追踪 span
+type Span interface {
+	// SetAttributes 设置属性
+	SetAttributes(attrs ...Attribute)
+	// RecordError 记录异常
+	RecordError(err error)
+	// End 结束 span
+	End()
+}
+
+type traceSpanKey struct{}
+
+// TraceHook 链路追踪中间件，每条 sql 语句创建一个 span
+type TraceHook struct {
+	tracer   Tracer
+	dbSystem string
+}
+
+// NewTraceHook 创建链路追踪中间件
+// dbSystem 数据库类型，如：mysql、sqlite
+func NewTraceHook(tracer Tracer, dbSystem string) *TraceHook {
+	return &TraceHook{
+		tracer:   tracer,
+		dbSystem: dbSystem,
+	}
+}
+
+// Before 执行前
+func (h *TraceHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	table := tableNameOf(ec)
+	spanName := string(ec.Type)
+	if table != "" {
+		spanName = spanName + " " + table
+	}
+	spanCtx, span := h.tracer.Start(ec.Context(ctx), spanName)
+	span.SetAttributes(
+		Attribute{Key: AttrDBSystem, Value: h.dbSystem},
+		Attribute{Key: AttrDBStatement, Value: ec.SQL},
+		Attribute{Key: AttrDBOperation, Value: string(ec.Type)},
+		Attribute{Key: AttrDBTable, Value: table},
+	)
+	ec.SetValue(traceSpanKey{}, span)
+	// 使用 span 上下文执行 sql，驱动和后续的 hook 中创建的 span 作为子 span
+	ec.SetContext(spanCtx)
+	return nil
+}
+
+// After 执行后
+func (h *TraceHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+	span, ok := ec.Value(traceSpanKey{}).(Span)
+	if !ok {
+		return
+	}
+	if ec.Type == engine.SELECT {
+		span.SetAttributes(Attribute{Key: AttrDBQueryRows, Value: er.QueryRows})
+	} else {
+		span.SetAttributes(Attribute{Key: AttrDBRowsAffected, Value: er.Affected})
+	}
+	if er.Err != nil {
+		span.SetAttributes(Attribute{Key: AttrError, Value: true})
+		span.RecordError(er.Err)
+	}
+	span.End()
+}
+
+// tableNameOf 获取执行的表名，执行上下文中没有表名时从 sql 中解析
+func tableNameOf(ec *engine.ExecutorContext) string {
+	if ec.TableName != "" {
+		return ec.TableName
+	}
+	return engine.ParseTableName(ec.SQL)
+}
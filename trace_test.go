@@ -0,0 +1,126 @@
+package daox_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/engine"
+)
+
+// memSpan 内存 span，用于测试
+type memSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+	ended bool
+}
+
+func (s *memSpan) SetAttributes(attrs ...daox.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *memSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *memSpan) End() {
+	s.ended = true
+}
+
+// memTracer 内存 exporter，记录所有 span
+type memTracer struct {
+	mu    sync.Mutex
+	spans []*memSpan
+}
+
+func (t *memTracer) Start(ctx context.Context, spanName string) (context.Context, daox.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &memSpan{name: spanName, attrs: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, memSpanKey{}, span), span
+}
+
+type memSpanKey struct{}
+
+// spanCheckHook 记录 Before 中上下文的 span，err 不为空时 Before 返回错误
+type spanCheckHook struct {
+	err  error
+	span any
+}
+
+func (h *spanCheckHook) Before(ctx context.Context, ec *engine.ExecutorContext) error {
+	h.span = ctx.Value(memSpanKey{})
+	return h.err
+}
+
+func (h *spanCheckHook) After(ctx context.Context, ec *engine.ExecutorContext, er *engine.ExecutorResult) {
+}
+
+func TestTraceHook(t *testing.T) {
+	tableName := "demo_info_trace"
+	before(t, tableName)
+	tracer := &memTracer{}
+	dao := daox.NewDao[*DemoInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(newDb()),
+		daox.WithHooks(daox.NewTraceHook(tracer, "sqlite")),
+	)
+	ok, err := dao.UpdateField(1, map[string]any{"name": "trace"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	var list []*DemoInfo
+	err = dao.ListByIDs(&list, 1, 2)
+	assert.NoError(t, err)
+	_, err = dao.UpdateField(1, map[string]any{"not_exist": "trace"})
+	assert.Error(t, err)
+
+	assert.Equal(t, 3, len(tracer.spans))
+	update := tracer.spans[0]
+	assert.Equal(t, "UPDATE "+tableName, update.name)
+	assert.Equal(t, "sqlite", update.attrs[daox.AttrDBSystem])
+	assert.Equal(t, tableName, update.attrs[daox.AttrDBTable])
+	assert.Equal(t, int64(1), update.attrs[daox.AttrDBRowsAffected])
+	assert.True(t, update.ended)
+
+	query := tracer.spans[1]
+	assert.Equal(t, "SELECT "+tableName, query.name)
+	assert.Equal(t, int64(2), query.attrs[daox.AttrDBQueryRows])
+
+	failed := tracer.spans[2]
+	assert.Equal(t, true, failed.attrs[daox.AttrError])
+	assert.Error(t, failed.err)
+	assert.True(t, failed.ended)
+}
+
+func TestTraceHookContext(t *testing.T) {
+	tableName := "demo_info_trace"
+	before(t, tableName)
+	tracer := &memTracer{}
+	check := &spanCheckHook{}
+	dao := daox.NewDao[*DemoInfo](tableName, "id",
+		daox.IsAutoIncrement(),
+		daox.WithDBMaster(newDb()),
+		daox.WithHooks(daox.NewTraceHook(tracer, "sqlite"), check),
+	)
+	// 后续的 hook 可以获取 span 上下文
+	_, err := dao.UpdateField(1, map[string]any{"name": "trace"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(tracer.spans))
+	assert.Same(t, tracer.spans[0], check.span)
+
+	// 后续的 hook 执行失败时结束 span
+	check.err = errors.New("before failed")
+	_, err = dao.UpdateField(1, map[string]any{"name": "trace"})
+	assert.ErrorIs(t, err, check.err)
+	assert.Equal(t, 2, len(tracer.spans))
+	assert.True(t, tracer.spans[1].ended)
+	assert.Equal(t, check.err, tracer.spans[1].err)
+}
@@ -34,6 +34,11 @@ func (t *Tx) GetContext(ctx context.Context, dest any, query string, args ...any
 	return doGet(ctx, t.Tx, dest, query, args, t.hook)
 }
 
+// StreamContext 流式查询，通过 fn 逐行处理结果
+func (t *Tx) StreamContext(ctx context.Context, fn engine.RowsHandler, query string, args ...any) error {
+	return doStream(ctx, t.Tx, fn, query, args, t.hook)
+}
+
 type txCtxKey struct{}
 
 // TxFun 事务处理函数
@@ -0,0 +1,147 @@
+package daox
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fengjx/daox/engine"
+	"github.com/fengjx/daox/sqlbuilder"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+// ModelPtr 约束 *T 实现了 Model 接口
+type ModelPtr[T any] interface {
+	*T
+	Model
+}
+
+// TypedDao 类型安全的 dao，查询直接返回 T 类型的结果，编译期检查参数类型
+// 底层通过 Dao 执行，可以通过 Dao() 方法获取
+type TypedDao[T any] struct {
+	dao *Dao
+}
+
+// NewTypedDao 创建类型安全的 dao 对象
+// eg: daox.NewTypedDao[User]("user", "id", daox.IsAutoIncrement())
+func NewTypedDao[T any, PT ModelPtr[T]](tableName string, primaryKey string, opts ...Option) *TypedDao[T] {
+	return &TypedDao[T]{
+		dao: NewDao[PT](tableName, primaryKey, opts...),
+	}
+}
+
+// Typed 将 dao 包装成类型安全的 dao
+// eg: daox.Typed[User](daox.NewDaoByMeta(meta.UserMeta))
+func Typed[T any, PT ModelPtr[T]](dao *Dao) *TypedDao[T] {
+	return &TypedDao[T]{
+		dao: dao,
+	}
+}
+
+// Dao 返回底层的 dao 对象
+func (d *TypedDao[T]) Dao() *Dao {
+	return d.dao
+}
+
+// Selector 创建当前表的查询构建器
+func (d *TypedDao[T]) Selector(columns ...string) *sqlbuilder.Selector {
+	return d.dao.Selector(columns...)
+}
+
+// Preload 返回查询时预加载关联数据的 dao
+func (d *TypedDao[T]) Preload(paths ...string) *TypedDao[T] {
+	return &TypedDao[T]{
+		dao: d.dao.Preload(paths...),
+	}
+}
+
+// WithExecutor 使用指定的执行器创建 dao，一般用于事务
+func (d *TypedDao[T]) WithExecutor(executor engine.Executor) *TypedDao[T] {
+	return &TypedDao[T]{
+		dao: d.dao.WithExecutor(executor),
+	}
+}
+
+// Get 根据 id 查询单条数据，数据不存在时返回 nil
+func (d *TypedDao[T]) Get(ctx context.Context, id any) (*T, error) {
+	return d.GetByColumn(ctx, OfKv(d.dao.TableMeta.PrimaryKey, id))
+}
+
+// GetByColumn 按指定字段查询单条数据，数据不存在时返回 nil
+func (d *TypedDao[T]) GetByColumn(ctx context.Context, kv *KV) (*T, error) {
+	dest := new(T)
+	exist, err := d.dao.GetByColumnContext(ctx, kv, any(dest).(Model))
+	if err != nil || !exist {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// First 查询第一条数据，数据不存在时返回 nil
+// selector 可以通过 Selector() 方法创建，limit 只设置在副本上，不会修改传入的 selector
+func (d *TypedDao[T]) First(ctx context.Context, selector *sqlbuilder.Selector) (*T, error) {
+	dest := new(T)
+	exist, err := selector.Clone().Limit(1).GetContext(ctx, dest)
+	if err != nil || !exist {
+		return nil, err
+	}
+	if err = d.dao.loadRelations(ctx, dest); err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// List 按条件查询多条数据
+func (d *TypedDao[T]) List(ctx context.Context, where sqlbuilder.ConditionBuilder) ([]T, error) {
+	return d.Find(ctx, d.dao.Selector().Where(where))
+}
+
+// Find 通过查询构建器查询多条数据
+// selector 可以通过 Selector() 方法创建
+func (d *TypedDao[T]) Find(ctx context.Context, selector *sqlbuilder.Selector) ([]T, error) {
+	var list []T
+	if err := selector.SelectContext(ctx, &list); err != nil {
+		return nil, err
+	}
+	if err := d.dao.loadRelations(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListByIDs 根据 id 查询多条数据
+func (d *TypedDao[T]) ListByIDs(ctx context.Context, ids ...any) ([]T, error) {
+	return d.ListByColumns(ctx, OfMultiKv(d.dao.TableMeta.PrimaryKey, ids...))
+}
+
+// ListByColumns 指定字段多个值查询多条数据
+func (d *TypedDao[T]) ListByColumns(ctx context.Context, kvs *MultiKV) ([]T, error) {
+	if kvs == nil || len(kvs.Values) == 0 {
+		return nil, nil
+	}
+	return d.List(ctx, ql.C(ql.Col(kvs.Key).In(kvs.Values...)))
+}
+
+// Save 插入数据，返回插入 id
+func (d *TypedDao[T]) Save(ctx context.Context, model *T, opts ...InsertOption) (int64, error) {
+	return d.dao.SaveContext(ctx, any(model).(Model), opts...)
+}
+
+// BatchSave 批量插入数据
+func (d *TypedDao[T]) BatchSave(ctx context.Context, models []*T, opts ...InsertOption) (sql.Result, error) {
+	return d.dao.BatchSaveContext(ctx, models, opts...)
+}
+
+// Update 全字段更新
+func (d *TypedDao[T]) Update(ctx context.Context, model *T, omitColumns ...string) (bool, error) {
+	return d.dao.UpdateContext(ctx, any(model).(Model), omitColumns...)
+}
+
+// UpdateField 部分字段更新
+func (d *TypedDao[T]) UpdateField(ctx context.Context, id any, fieldMap map[string]any) (bool, error) {
+	return d.dao.UpdateFieldContext(ctx, id, fieldMap)
+}
+
+// DeleteByID 根据 id 删除数据
+func (d *TypedDao[T]) DeleteByID(ctx context.Context, id any) (bool, error) {
+	return d.dao.DeleteByIDContext(ctx, id)
+}
@@ -0,0 +1,104 @@
+package daox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fengjx/daox"
+	"github.com/fengjx/daox/sqlbuilder/ql"
+)
+
+type typedUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (m *typedUser) GetID() any {
+	return m.ID
+}
+
+func TestTypedDao(t *testing.T) {
+	ctx := context.Background()
+	db := newDb()
+	tableName := "typed_user"
+	after(t, tableName)
+	_, err := db.Exec("CREATE TABLE typed_user (id integer primary key autoincrement, name text, age integer)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer after(t, tableName)
+
+	dao := daox.NewTypedDao[typedUser](tableName, "id", daox.IsAutoIncrement(), daox.WithDBMaster(db))
+	id, err := dao.Save(ctx, &typedUser{Name: "u1", Age: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	_, err = dao.BatchSave(ctx, []*typedUser{
+		{Name: "u2", Age: 20},
+		{Name: "u3", Age: 30},
+	})
+	assert.NoError(t, err)
+
+	user, err := dao.Get(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "u2", user.Name)
+
+	user, err = dao.Get(ctx, 100)
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+
+	user, err = dao.GetByColumn(ctx, daox.OfKv("name", "u3"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), user.ID)
+
+	list, err := dao.List(ctx, ql.C(ql.Col("age").GT(10)))
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	list, err = dao.ListByIDs(ctx, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []typedUser{
+		{ID: 1, Name: "u1", Age: 10},
+		{ID: 3, Name: "u3", Age: 30},
+	}, list)
+
+	selector := dao.Selector().OrderBy(ql.Desc("age"))
+	user, err = dao.First(ctx, selector)
+	assert.NoError(t, err)
+	assert.Equal(t, "u3", user.Name)
+	// First 不会修改传入的 selector
+	list, err = dao.Find(ctx, selector)
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+
+	list, err = dao.Find(ctx, dao.Selector().Where(ql.C(ql.Col("age").LT(30))))
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	user.Age = 31
+	ok, err := dao.Update(ctx, user)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = dao.UpdateField(ctx, 1, map[string]any{"age": 11})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	user, err = dao.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, user.Age)
+
+	ok, err = dao.DeleteByID(ctx, 2)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	user, err = dao.Get(ctx, 2)
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+
+	// 包装已有 dao
+	typed := daox.Typed[typedUser](dao.Dao())
+	list, err = typed.ListByIDs(ctx, 1, 2, 3)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, 31, list[1].Age)
+}